@@ -4,4 +4,9 @@ go 1.20
 
 require hint v0.0.0
 
+require (
+	google.golang.org/protobuf v1.31.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
 replace hint => ./hint
@@ -0,0 +1,68 @@
+package hint
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// 对应 hint.go 中分组的例子: 以/admin开头的路由需要鉴权，e.g.
+//
+//	admin := e.Group("/admin")
+//	admin.Use(hint.BasicAuth(hint.Accounts{"hg": "secret"}))
+//	admin.GET("/dashboard", func(c *hint.Context) {
+//		c.String(http.StatusOK, "hello %s", c.GetString(hint.AuthUserKey))
+//	})
+//
+// 密码比较使用固定时间的比较，用户名不存在时同样完成一次比较，避免通过响应时间猜测账号。
+
+// AuthUserKey is the key of the authenticated user name in Context.Keys
+const AuthUserKey = "user"
+
+// Accounts defines a key/value for user/pass list of authorized logins
+type Accounts map[string]string
+
+// BasicAuth returns a Basic HTTP Authorization middleware with the realm "Authorization Required"
+func BasicAuth(accounts Accounts) HandlerFunc {
+	return BasicAuthForRealm(accounts, "")
+}
+
+// BasicAuthForRealm returns a Basic HTTP Authorization middleware, the user name is set with AuthUserKey
+// the realm is shown by the browser in the login dialog, "Authorization Required" by default
+// failed requests are aborted with 401 and the WWW-Authenticate header
+func BasicAuthForRealm(accounts Accounts, realm string) HandlerFunc {
+	if len(accounts) == 0 {
+		panic("BasicAuth: empty list of authorized credentials")
+	}
+	if realm == "" {
+		realm = "Authorization Required"
+	}
+	challenge := "Basic realm=" + strconv.Quote(realm) + `, charset="UTF-8"`
+	// passwords are hashed to compare in constant time regardless of their lengths
+	hashed := make(map[string][sha256.Size]byte, len(accounts))
+	for user, password := range accounts {
+		if user == "" {
+			panic("BasicAuth: user can not be empty")
+		}
+		hashed[user] = sha256.Sum256([]byte(password))
+	}
+
+	return func(c *Context) {
+		user, password, ok := c.Req.BasicAuth()
+		if ok {
+			expected, found := hashed[user]
+			given := sha256.Sum256([]byte(password))
+			ok = subtle.ConstantTimeCompare(given[:], expected[:]) == 1 && found
+		}
+		if !ok {
+			c.Error(errors.New("basic auth: invalid credentials")).SetMeta(H{"client_ip": c.ClientIP(), "user": user})
+			c.Writer.Header().Set("WWW-Authenticate", challenge)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Set(AuthUserKey, user)
+		c.Next()
+	}
+}
@@ -0,0 +1,43 @@
+package hint
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBasicAuth(t *testing.T) {
+	e := New()
+	admin := e.Group("/admin")
+	admin.Use(BasicAuthForRealm(Accounts{"hg": "secret"}, "admin"))
+	admin.GET("/dashboard", func(c *Context) {
+		c.String(http.StatusOK, c.GetString(AuthUserKey))
+	})
+
+	tests := []struct {
+		user, password string
+		code           int
+	}{
+		{"hg", "secret", http.StatusOK},
+		{"hg", "wrong", http.StatusUnauthorized},
+		{"unknown", "secret", http.StatusUnauthorized},
+		{"", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/admin/dashboard", nil)
+		if tt.user != "" {
+			req.SetBasicAuth(tt.user, tt.password)
+		}
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Fatalf("%s:%s expect %d, got %d", tt.user, tt.password, tt.code, w.Code)
+		}
+		if tt.code == http.StatusOK && w.Body.String() != tt.user {
+			t.Fatalf("unexpected user %q", w.Body.String())
+		}
+		if tt.code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") != `Basic realm="admin", charset="UTF-8"` {
+			t.Fatalf("unexpected WWW-Authenticate %q", w.Header().Get("WWW-Authenticate"))
+		}
+	}
+}
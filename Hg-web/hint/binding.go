@@ -0,0 +1,225 @@
+package hint
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// 绑定(binding)负责把请求中的数据填充到结构体中，避免在 handler 中逐个调用 Query/PostForm 取值。
+// 数据来源由 Binding 决定: JSON/XML 来自 body，form 来自 query 与表单，uri 来自路由参数，header 来自请求头。
+// 字段名通过 struct tag 指定，例如 `json:"name"` `form:"name"` `uri:"id"` `header:"X-Token"`。
+// 填充完成后根据 `binding:"required,min=3"` 进行校验，详见 validator.go。
+
+// Content-Type MIME of the most common data formats
+const (
+	MIMEJSON              = "application/json"
+	MIMEXML               = "application/xml"
+	MIMEXML2              = "text/xml"
+	MIMEHTML              = "text/html"
+	MIMEPlain             = "text/plain"
+	MIMEYAML              = "application/yaml"
+	MIMEPROTOBUF          = "application/x-protobuf"
+	MIMEPOSTForm          = "application/x-www-form-urlencoded"
+	MIMEMultipartPOSTForm = "multipart/form-data"
+)
+
+// defaultMemory is the max memory used when parsing multipart form, the default of Engine.MaxMultipartMemory
+// Context parses the form with Engine.MaxMultipartMemory before binding, see Context.ShouldBindWith
+const defaultMemory = 32 << 20
+
+// Binding describes the interface which needs to be implemented for binding the data present in the request
+// such as JSON request body, query parameters or the form POST
+type Binding interface {
+	Name() string
+	Bind(req *http.Request, obj interface{}) error
+}
+
+// BindingBody adds BindBody method to Binding, BindBody is similar with Bind
+// but it reads the body from supplied bytes instead of req.Body
+type BindingBody interface {
+	Binding
+	BindBody(body []byte, obj interface{}) error
+}
+
+// These implement the Binding interface and can be used to bind the data present in the request to struct instances
+var (
+	JSONBinding          BindingBody = jsonBinding{}
+	XMLBinding           BindingBody = xmlBinding{}
+	FormBinding          Binding     = formBinding{}
+	FormPostBinding      Binding     = formPostBinding{}
+	FormMultipartBinding Binding     = formMultipartBinding{}
+	QueryBinding         Binding     = queryBinding{}
+	HeaderBinding        Binding     = headerBinding{}
+)
+
+// bindingDefault returns the appropriate Binding instance based on the http method and the content type
+func bindingDefault(method string, contentType string) Binding {
+	if method == http.MethodGet {
+		return FormBinding
+	}
+	switch filterFlags(contentType) {
+	case MIMEJSON:
+		return JSONBinding
+	case MIMEXML, MIMEXML2:
+		return XMLBinding
+	case MIMEMultipartPOSTForm:
+		return FormMultipartBinding
+	default: // MIMEPOSTForm
+		return FormBinding
+	}
+}
+
+// filterFlags returns the MIME type without parameters
+// e.g. application/json; charset=utf-8 -> application/json
+func filterFlags(content string) string {
+	if i := strings.IndexAny(content, "; "); i >= 0 {
+		return content[:i]
+	}
+	return content
+}
+
+type jsonBinding struct{}
+
+func (jsonBinding) Name() string {
+	return "json"
+}
+
+func (jsonBinding) Bind(req *http.Request, obj interface{}) error {
+	if req == nil || req.Body == nil {
+		return errors.New("invalid request")
+	}
+	return decodeJSON(req.Body, obj)
+}
+
+func (jsonBinding) BindBody(body []byte, obj interface{}) error {
+	return decodeJSON(bytes.NewReader(body), obj)
+}
+
+func decodeJSON(r io.Reader, obj interface{}) error {
+	if err := json.NewDecoder(r).Decode(obj); err != nil {
+		return err
+	}
+	return validate(obj)
+}
+
+type xmlBinding struct{}
+
+func (xmlBinding) Name() string {
+	return "xml"
+}
+
+func (xmlBinding) Bind(req *http.Request, obj interface{}) error {
+	if req == nil || req.Body == nil {
+		return errors.New("invalid request")
+	}
+	return decodeXML(req.Body, obj)
+}
+
+func (xmlBinding) BindBody(body []byte, obj interface{}) error {
+	return decodeXML(bytes.NewReader(body), obj)
+}
+
+func decodeXML(r io.Reader, obj interface{}) error {
+	if err := xml.NewDecoder(r).Decode(obj); err != nil {
+		return err
+	}
+	return validate(obj)
+}
+
+type formBinding struct{}
+
+func (formBinding) Name() string {
+	return "form"
+}
+
+// Bind maps both query string and POST form, multipart form is parsed when the content type is multipart
+func (formBinding) Bind(req *http.Request, obj interface{}) error {
+	if err := req.ParseForm(); err != nil {
+		return err
+	}
+	if err := req.ParseMultipartForm(defaultMemory); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		return err
+	}
+	if err := mapForm(obj, req.Form, "form"); err != nil {
+		return err
+	}
+	return validate(obj)
+}
+
+type formPostBinding struct{}
+
+func (formPostBinding) Name() string {
+	return "form-urlencoded"
+}
+
+// Bind maps only POST form, query string is ignored
+func (formPostBinding) Bind(req *http.Request, obj interface{}) error {
+	if err := req.ParseForm(); err != nil {
+		return err
+	}
+	if err := mapForm(obj, req.PostForm, "form"); err != nil {
+		return err
+	}
+	return validate(obj)
+}
+
+type formMultipartBinding struct{}
+
+func (formMultipartBinding) Name() string {
+	return "multipart/form-data"
+}
+
+// Bind maps multipart values and files, files are bound to *multipart.FileHeader or []*multipart.FileHeader
+func (formMultipartBinding) Bind(req *http.Request, obj interface{}) error {
+	if err := req.ParseMultipartForm(defaultMemory); err != nil {
+		return err
+	}
+	if err := mapMultipart(obj, req.MultipartForm); err != nil {
+		return err
+	}
+	return validate(obj)
+}
+
+type queryBinding struct{}
+
+func (queryBinding) Name() string {
+	return "query"
+}
+
+func (queryBinding) Bind(req *http.Request, obj interface{}) error {
+	if err := mapForm(obj, req.URL.Query(), "form"); err != nil {
+		return err
+	}
+	return validate(obj)
+}
+
+type headerBinding struct{}
+
+func (headerBinding) Name() string {
+	return "header"
+}
+
+// Bind maps request headers, the names in `header` tag are canonicalized e.g. x-token -> X-Token
+func (headerBinding) Bind(req *http.Request, obj interface{}) error {
+	if err := mapHeader(obj, req.Header); err != nil {
+		return err
+	}
+	return validate(obj)
+}
+
+// bindURI maps the router params e.g. /user/:id with `uri:"id"`
+func bindURI(params Params, obj interface{}) error {
+	values := make(map[string][]string, len(params))
+	for _, p := range params {
+		values[p.Key] = append(values[p.Key], p.Value)
+	}
+	if err := mapForm(obj, values, "uri"); err != nil {
+		return err
+	}
+	return validate(obj)
+}
@@ -0,0 +1,296 @@
+package hint
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 通过反射把 map[string][]string 形式的数据(query、表单、路由参数、请求头)映射到结构体字段。
+// tag 格式: `form:"name,default=value"`，"-" 表示忽略该字段，缺省时使用字段名。
+// 匿名嵌入或指定了 tag 的结构体字段会递归映射(tag 的名字不作为前缀)，未指定 tag 的具名结构体字段被忽略。
+// 递归时记录当前路径上的结构体类型，自引用类型(e.g. type Node struct{ Next *Node })不会无限递归。
+// time.Time 字段可以通过 `time_format:"2006-01-02"` 指定格式，缺省为 RFC3339。
+
+var errUnknownType = errors.New("unknown type")
+
+var (
+	fileHeaderType      = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeaderSliceType = reflect.TypeOf([]*multipart.FileHeader(nil))
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// mapForm maps values to obj by tag, obj must be a pointer to struct
+func mapForm(obj interface{}, values map[string][]string, tag string) error {
+	return mapFormFiles(obj, values, nil, tag)
+}
+
+// mapMultipart maps values and files of a multipart form to obj
+func mapMultipart(obj interface{}, form *multipart.Form) error {
+	return mapFormFiles(obj, form.Value, form.File, "form")
+}
+
+// mapHeader maps headers to obj, the names are canonicalized before looking up
+func mapHeader(obj interface{}, h http.Header) error {
+	values := make(map[string][]string, len(h))
+	for k, v := range h {
+		values[textproto.CanonicalMIMEHeaderKey(k)] = v
+	}
+	return mapFormFiles(obj, values, nil, "header")
+}
+
+func mapFormFiles(obj interface{}, values map[string][]string, files map[string][]*multipart.FileHeader, tag string) error {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("binding: %T is not a pointer to struct", obj)
+	}
+	return mapStruct(v.Elem(), values, files, tag, map[reflect.Type]bool{})
+}
+
+// visiting are the struct types on the current path of recursion, they are not entered again to stop cycles
+func mapStruct(v reflect.Value, values map[string][]string, files map[string][]*multipart.FileHeader, tag string, visiting map[reflect.Type]bool) error {
+	t := v.Type()
+	visiting[t] = true
+	defer delete(visiting, t)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous { // unexported
+			continue
+		}
+		name, opts := sf.Tag.Get(tag), ""
+		if name == "-" {
+			continue
+		}
+		if idx := strings.Index(name, ","); idx >= 0 {
+			name, opts = name[:idx], name[idx+1:]
+		}
+		field := v.Field(i)
+
+		// embedded or tagged struct is mapped recursively
+		if isNestedStruct(sf.Type) {
+			if !sf.Anonymous && name == "" {
+				continue
+			}
+			elem := sf.Type
+			if elem.Kind() == reflect.Ptr {
+				elem = elem.Elem()
+			}
+			if visiting[elem] {
+				continue
+			}
+			if field.Kind() == reflect.Ptr {
+				if field.IsNil() {
+					// e.g. unexported embedded pointer
+					if !field.CanSet() {
+						continue
+					}
+					field.Set(reflect.New(elem))
+				}
+				field = field.Elem()
+			}
+			if err := mapStruct(field, values, files, tag, visiting); err != nil {
+				return err
+			}
+			continue
+		}
+		// e.g. unexported embedded non-struct type
+		if !field.CanSet() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		if tag == "header" {
+			name = textproto.CanonicalMIMEHeaderKey(name)
+		}
+
+		if sf.Type == fileHeaderType || sf.Type == fileHeaderSliceType {
+			if fhs := files[name]; len(fhs) > 0 {
+				if sf.Type == fileHeaderType {
+					field.Set(reflect.ValueOf(fhs[0]))
+				} else {
+					field.Set(reflect.ValueOf(fhs))
+				}
+			}
+			continue
+		}
+
+		vs, ok := values[name]
+		if !ok || len(vs) == 0 {
+			def, found := defaultOption(opts)
+			if !found {
+				continue
+			}
+			vs = []string{def}
+		}
+		if err := setField(field, sf, vs); err != nil {
+			return fmt.Errorf("binding: field %s: %w", sf.Name, err)
+		}
+	}
+	return nil
+}
+
+// isNestedStruct reports whether t is a struct which should be mapped recursively
+func isNestedStruct(t reflect.Type) bool {
+	if t == fileHeaderType {
+		return false
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == timeType {
+		return false
+	}
+	return !reflect.PtrTo(t).Implements(textUnmarshalerType)
+}
+
+// defaultOption returns the value of "default=" in tag options
+// e.g. form:"page,default=1"
+func defaultOption(opts string) (string, bool) {
+	for _, opt := range strings.Split(opts, ",") {
+		if strings.HasPrefix(opt, "default=") {
+			return opt[len("default="):], true
+		}
+	}
+	return "", false
+}
+
+func setField(field reflect.Value, sf reflect.StructField, vs []string) error {
+	switch field.Kind() {
+	case reflect.Slice:
+		if field.Type().Elem().Kind() == reflect.Uint8 { // []byte
+			field.SetBytes([]byte(vs[0]))
+			return nil
+		}
+		slice := reflect.MakeSlice(field.Type(), len(vs), len(vs))
+		for i, s := range vs {
+			if err := setValue(slice.Index(i), sf, s); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	case reflect.Array:
+		if len(vs) != field.Len() {
+			return fmt.Errorf("%q is not valid value for %s", vs, field.Type())
+		}
+		for i, s := range vs {
+			if err := setValue(field.Index(i), sf, s); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return setValue(field, sf, vs[0])
+	}
+}
+
+func setValue(v reflect.Value, sf reflect.StructField, s string) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setValue(v.Elem(), sf, s)
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) && v.Type() != timeType {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	switch v.Type() {
+	case timeType:
+		return setTime(v, sf, s)
+	case durationType:
+		if s == "" {
+			s = "0"
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		if s == "" {
+			s = "false"
+		}
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if s == "" {
+			s = "0"
+		}
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if s == "" {
+			s = "0"
+		}
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		if s == "" {
+			s = "0"
+		}
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return errUnknownType
+	}
+	return nil
+}
+
+// setTime parses s with `time_format` tag, "unix" and "unixnano" are supported as well
+func setTime(v reflect.Value, sf reflect.StructField, s string) error {
+	if s == "" {
+		v.Set(reflect.ValueOf(time.Time{}))
+		return nil
+	}
+	layout := sf.Tag.Get("time_format")
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	var t time.Time
+	switch layout {
+	case "unix", "unixnano":
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		if layout == "unix" {
+			t = time.Unix(n, 0)
+		} else {
+			t = time.Unix(0, n)
+		}
+	default:
+		var err error
+		if t, err = time.Parse(layout, s); err != nil {
+			return err
+		}
+	}
+	v.Set(reflect.ValueOf(t))
+	return nil
+}
@@ -0,0 +1,231 @@
+package hint
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+type bindAddress struct {
+	City string `json:"city" form:"city" binding:"required"`
+}
+
+type bindUser struct {
+	Name    string      `json:"name" xml:"name" form:"name" binding:"required,min=2,max=8"`
+	Age     int         `json:"age" xml:"age" form:"age,default=18" binding:"min=1,max=150"`
+	Email   string      `json:"email" xml:"email" form:"email" binding:"omitempty,email"`
+	Role    string      `json:"role" xml:"role" form:"role" binding:"omitempty,oneof=admin user"`
+	Code    string      `json:"code" xml:"code" form:"code" binding:"omitempty,len=4,regexp=^[0-9]{2,4}$"`
+	Tags    []string    `json:"tags" xml:"tags" form:"tag"`
+	Address bindAddress `json:"address" xml:"-" form:"address"`
+}
+
+func newBindContext(method, target string, body io.Reader, contentType string) *Context {
+	req := httptest.NewRequest(method, target, body)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return newContext(httptest.NewRecorder(), req)
+}
+
+func TestBindJSONAndValidate(t *testing.T) {
+	body := `{"name":"hg","age":24,"email":"hg@example.com","role":"admin","code":"2023","address":{"city":"sh"}}`
+	c := newBindContext(http.MethodPost, "/", strings.NewReader(body), MIMEJSON+"; charset=utf-8")
+	var u bindUser
+	if err := c.ShouldBind(&u); err != nil {
+		t.Fatal(err)
+	}
+	if u.Name != "hg" || u.Age != 24 || u.Address.City != "sh" {
+		t.Fatalf("unexpected result %+v", u)
+	}
+
+	// body can be read again after binding
+	raw, err := io.ReadAll(c.Req.Body)
+	if err != nil || string(raw) != body {
+		t.Fatalf("body can not be read again, got %q %v", raw, err)
+	}
+}
+
+func TestValidationErrors(t *testing.T) {
+	body := `{"name":"h","age":200,"email":"hg@","role":"root","code":"20a3"}`
+	c := newBindContext(http.MethodPost, "/", strings.NewReader(body), MIMEJSON)
+	var u bindUser
+	err := c.ShouldBindJSON(&u)
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expect ValidationErrors, got %v", err)
+	}
+	expected := []FieldError{
+		{Field: "bindUser.Name", Tag: "min", Param: "2"},
+		{Field: "bindUser.Age", Tag: "max", Param: "150"},
+		{Field: "bindUser.Email", Tag: "email"},
+		{Field: "bindUser.Role", Tag: "oneof", Param: "admin user"},
+		{Field: "bindUser.Code", Tag: "regexp", Param: "^[0-9]{2,4}$"},
+		{Field: "bindUser.Address.City", Tag: "required"},
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expect %d errors, got %v", len(expected), errs)
+	}
+	for i, fe := range errs {
+		if fe.Field != expected[i].Field || fe.Tag != expected[i].Tag || fe.Param != expected[i].Param {
+			t.Errorf("expect %v, got %v", expected[i], fe)
+		}
+	}
+}
+
+func TestBindXMLWithCachedBody(t *testing.T) {
+	body := `<user><name>hg</name><age>24</age></user>`
+	c := newBindContext(http.MethodPost, "/", strings.NewReader(body), MIMEXML)
+	var j bindUser
+	if err := c.ShouldBindBodyWith(&j, JSONBinding); err == nil {
+		t.Fatal("expect json binding to fail")
+	}
+	var x struct {
+		Name string `xml:"name" binding:"required"`
+		Age  int    `xml:"age"`
+	}
+	if err := c.ShouldBindBodyWith(&x, XMLBinding); err != nil {
+		t.Fatal(err)
+	}
+	if x.Name != "hg" || x.Age != 24 {
+		t.Fatalf("unexpected result %+v", x)
+	}
+}
+
+func TestBindQueryAndForm(t *testing.T) {
+	c := newBindContext(http.MethodGet, "/?name=hg&tag=a&tag=b", nil, "")
+	var u bindUser
+	u.Address.City = "sh"
+	if err := c.ShouldBind(&u); err != nil {
+		t.Fatal(err)
+	}
+	if u.Name != "hg" || u.Age != 18 || len(u.Tags) != 2 || u.Tags[1] != "b" {
+		t.Fatalf("unexpected result %+v", u)
+	}
+
+	form := url.Values{"name": {"axg"}, "age": {"23"}, "city": {"bj"}}
+	c = newBindContext(http.MethodPost, "/", strings.NewReader(form.Encode()), MIMEPOSTForm)
+	var f bindUser
+	if err := c.ShouldBind(&f); err != nil {
+		t.Fatal(err)
+	}
+	if f.Name != "axg" || f.Age != 23 || f.Address.City != "bj" {
+		t.Fatalf("unexpected result %+v", f)
+	}
+}
+
+func TestBindMultipart(t *testing.T) {
+	buf := new(bytes.Buffer)
+	mw := multipart.NewWriter(buf)
+	_ = mw.WriteField("name", "hg")
+	fw, _ := mw.CreateFormFile("avatar", "avatar.png")
+	_, _ = fw.Write([]byte("png"))
+	_ = mw.Close()
+
+	c := newBindContext(http.MethodPost, "/", buf, mw.FormDataContentType())
+	var obj struct {
+		Name   string                `form:"name" binding:"required"`
+		Avatar *multipart.FileHeader `form:"avatar" binding:"required"`
+	}
+	if err := c.ShouldBind(&obj); err != nil {
+		t.Fatal(err)
+	}
+	if obj.Name != "hg" || obj.Avatar.Filename != "avatar.png" {
+		t.Fatalf("unexpected result %+v", obj)
+	}
+}
+
+// bindNode refers to itself, binding must not recurse forever
+type bindNode struct {
+	Name     string    `form:"name"`
+	Next     *bindNode `form:"next"`
+	Prev     *bindNode
+	Children []bindNode `form:"-"`
+}
+
+type bindInner struct {
+	A string `form:"a"`
+}
+
+type bindLabel string
+
+func TestBindNestedStruct(t *testing.T) {
+	c := newBindContext(http.MethodGet, "/?name=x&a=1&b=2&city=sh", nil, "")
+	var node bindNode
+	if err := c.ShouldBind(&node); err != nil {
+		t.Fatal(err)
+	}
+	if node.Name != "x" || node.Next != nil || node.Prev != nil {
+		t.Fatalf("unexpected result %+v", node)
+	}
+
+	// unexported embedded fields are skipped instead of panicking
+	var embedded struct {
+		*bindInner
+		bindLabel
+		B       string       `form:"b"`
+		Address *bindAddress `form:"address"`
+	}
+	if err := c.ShouldBind(&embedded); err != nil {
+		t.Fatal(err)
+	}
+	if embedded.bindInner != nil || embedded.bindLabel != "" || embedded.B != "2" || embedded.Address.City != "sh" {
+		t.Fatalf("unexpected result %+v", embedded)
+	}
+
+	// the exported fields of an allocated unexported embedded pointer are still bound
+	embedded.bindInner = &bindInner{}
+	if err := c.ShouldBind(&embedded); err != nil || embedded.A != "1" {
+		t.Fatalf("unexpected result %+v %v", embedded, err)
+	}
+}
+
+func TestBindUriAndHeader(t *testing.T) {
+	c := newBindContext(http.MethodGet, "/user/42", nil, "")
+	c.Params = Params{{Key: "id", Value: "42"}}
+	c.Req.Header.Set("X-Request-Since", "2023-06-27")
+	c.Req.Header.Set("X-Timeout", "3s")
+
+	var uri struct {
+		ID uint64 `uri:"id" binding:"required"`
+	}
+	if err := c.ShouldBindUri(&uri); err != nil || uri.ID != 42 {
+		t.Fatalf("unexpected result %+v %v", uri, err)
+	}
+
+	var h struct {
+		Since   time.Time     `header:"x-request-since" time_format:"2006-01-02"`
+		Timeout time.Duration `header:"X-Timeout"`
+		Token   *string       `header:"X-Token"`
+	}
+	if err := c.ShouldBindHeader(&h); err != nil {
+		t.Fatal(err)
+	}
+	if h.Since.Day() != 27 || h.Timeout != 3*time.Second || h.Token != nil {
+		t.Fatalf("unexpected result %+v", h)
+	}
+}
+
+func TestBindWritesBadRequest(t *testing.T) {
+	e := New()
+	e.POST("/user", func(c *Context) {
+		var u bindUser
+		if err := c.BindJSON(&u); err != nil {
+			return
+		}
+		c.String(http.StatusOK, u.Name)
+	})
+	req := httptest.NewRequest(http.MethodPost, "/user", strings.NewReader(`{}`))
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "required") {
+		t.Fatalf("expect 400 with validation message, got %d %q", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,142 @@
+package hint
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// 部署在负载均衡/反向代理之后时，RemoteAddr 是代理的地址，真实的客户端地址由代理写在请求头中。
+// 请求头可以被客户端伪造，因此只有当直接连接的对端(RemoteAddr)属于可信代理时，才读取这些请求头。
+// X-Forwarded-For 与 Forwarded 中的地址从右往左检查，跳过可信代理，第一个不可信的地址即为客户端地址，
+// e.g. 可信代理 10.0.0.0/8，X-Forwarded-For: 1.2.3.4, 5.6.7.8, 10.0.0.2 -> 5.6.7.8
+// 默认不信任任何代理，ClientIP 与 RemoteIP 相同。
+
+// defaultRemoteIPHeaders are the headers checked by ClientIP in order
+var defaultRemoteIPHeaders = []string{"X-Forwarded-For", "X-Real-IP", "Forwarded"}
+
+// SetTrustedProxies sets the proxies whose headers carrying the client ip are trusted
+// a proxy is an IP or a CIDR, e.g. 10.0.0.1, 10.0.0.0/8, fd00::/8, nil means trusting no proxy
+func (e *Engine) SetTrustedProxies(proxies []string) error {
+	cidrs := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return &net.ParseError{Type: "IP address", Text: proxy}
+			}
+			if ip4 := ip.To4(); ip4 != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, cidr, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return err
+		}
+		cidrs = append(cidrs, cidr)
+	}
+	e.trustedCIDRs = cidrs
+	return nil
+}
+
+// isTrustedProxy reports whether the ip is in the trusted proxies
+func (e *Engine) isTrustedProxy(ip net.IP) bool {
+	for _, cidr := range e.trustedCIDRs {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP resolves the client ip of req, see Context.ClientIP
+func (e *Engine) clientIP(req *http.Request) string {
+	remote := remoteIP(req)
+	ip := net.ParseIP(remote)
+	if ip == nil || !e.isTrustedProxy(ip) {
+		return remote
+	}
+	for _, header := range e.RemoteIPHeaders {
+		var addrs []string
+		switch http.CanonicalHeaderKey(header) {
+		case "Forwarded":
+			addrs = parseForwarded(req.Header.Values(header))
+		default:
+			for _, value := range req.Header.Values(header) {
+				addrs = append(addrs, strings.Split(value, ",")...)
+			}
+		}
+		if client, ok := e.lastUntrusted(addrs); ok {
+			return client
+		}
+	}
+	return remote
+}
+
+// lastUntrusted walks addrs from right to left and returns the first address not in the trusted proxies
+// the leftmost address is returned when all of them are trusted, ok is false when any address is invalid
+func (e *Engine) lastUntrusted(addrs []string) (client string, ok bool) {
+	for i := len(addrs) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(addrs[i]))
+		if ip == nil {
+			return "", false
+		}
+		client = ip.String()
+		if !e.isTrustedProxy(ip) {
+			return client, true
+		}
+	}
+	return client, client != ""
+}
+
+// parseForwarded returns the "for" addresses of RFC 7239 Forwarded headers, ports and brackets are stripped
+// e.g. for=192.0.2.60;proto=http, for="[2001:db8:cafe::17]:4711" -> 192.0.2.60, 2001:db8:cafe::17
+// obfuscated identifiers like "unknown" or "_hidden" are kept, so that they are rejected as invalid ip
+func parseForwarded(values []string) []string {
+	var addrs []string
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			for _, pair := range strings.Split(element, ";") {
+				key, node, found := strings.Cut(strings.TrimSpace(pair), "=")
+				if !found || !strings.EqualFold(key, "for") {
+					continue
+				}
+				node = strings.Trim(node, `"`)
+				if strings.HasPrefix(node, "[") {
+					if end := strings.IndexByte(node, ']'); end > 0 {
+						node = node[1:end]
+					}
+				} else if host, _, err := net.SplitHostPort(node); err == nil {
+					node = host
+				}
+				addrs = append(addrs, node)
+			}
+		}
+	}
+	return addrs
+}
+
+// ClientIP returns the ip of the client, the headers of RemoteIPHeaders are used only when
+// the request comes from a trusted proxy, see Engine.SetTrustedProxies
+func (c *Context) ClientIP() string {
+	if c.e == nil {
+		return remoteIP(c.Req)
+	}
+	return c.e.clientIP(c.Req)
+}
+
+// RemoteIP returns the ip of the direct peer (Req.RemoteAddr) without port
+func (c *Context) RemoteIP() string {
+	return remoteIP(c.Req)
+}
+
+// remoteIP returns the ip of the direct peer
+func remoteIP(req *http.Request) string {
+	ip, _, err := net.SplitHostPort(strings.TrimSpace(req.RemoteAddr))
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return ip
+}
@@ -0,0 +1,48 @@
+package hint
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	e := New()
+	if err := e.SetTrustedProxies([]string{"10.0.0.0/8", "fd00::1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.SetTrustedProxies([]string{"10.0.0.0/8", "fd00::1", "proxy"}); err == nil {
+		t.Fatal("expect error of invalid proxy")
+	}
+	e.GET("/ip", func(c *Context) {
+		c.String(http.StatusOK, c.ClientIP())
+	})
+
+	tests := []struct {
+		name   string
+		remote string
+		header http.Header
+		ip     string
+	}{
+		{"untrusted peer", "1.1.1.1:80", http.Header{"X-Forwarded-For": {"2.2.2.2"}}, "1.1.1.1"},
+		{"no header", "10.0.0.1:80", nil, "10.0.0.1"},
+		{"xff skips trusted", "10.0.0.1:80", http.Header{"X-Forwarded-For": {"6.6.6.6, 2.2.2.2, 10.0.0.2"}}, "2.2.2.2"},
+		{"xff all trusted", "10.0.0.1:80", http.Header{"X-Forwarded-For": {"10.0.0.3, 10.0.0.2"}}, "10.0.0.3"},
+		{"xff multi lines", "10.0.0.1:80", http.Header{"X-Forwarded-For": {"2.2.2.2", "3.3.3.3"}}, "3.3.3.3"},
+		{"xff invalid", "10.0.0.1:80", http.Header{"X-Forwarded-For": {"junk"}, "X-Real-Ip": {"4.4.4.4"}}, "4.4.4.4"},
+		{"forwarded", "[fd00::1]:80", http.Header{"Forwarded": {`for=192.0.2.60;proto=http, for="[2001:db8:cafe::17]:4711"`}}, "2001:db8:cafe::17"},
+		{"forwarded obfuscated", "10.0.0.1:80", http.Header{"Forwarded": {"for=_hidden"}}, "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+		req.RemoteAddr = tt.remote
+		for k, v := range tt.header {
+			req.Header[k] = v
+		}
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		if w.Body.String() != tt.ip {
+			t.Errorf("%s: expect %s, got %s", tt.name, tt.ip, w.Body.String())
+		}
+	}
+}
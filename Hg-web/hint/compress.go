@@ -0,0 +1,294 @@
+package hint
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 响应压缩: 根据请求的 Accept-Encoding 协商 gzip 或 deflate。
+// 响应的前 MinLength 字节先缓存下来，超过 MinLength 时才决定是否压缩，过小的响应压缩后反而更大。
+// 图片、视频、压缩包等已经压缩过的类型，以及 handler 自己设置了 Content-Encoding 的响应不再压缩。
+// 调用 Flush(例如流式响应)时不再等待 MinLength，直接决定是否压缩并发送。
+// HTTP 的 deflate 编码指的是 zlib 格式(RFC 9110 §8.4.1.2)，而不是 compress/flate 的裸 DEFLATE 数据。
+// 请求头带有 Content-Encoding: gzip 时，请求体会被透明地解压，Bind 等方法读取到的是解压后的内容。
+// 解压后的大小受 MaxDecompressedSize 限制，防止很小的压缩包(zip bomb)解压后占满内存，BodyLimit 只能限制压缩后的大小。
+
+const (
+	encodingGzip    = "gzip"
+	encodingDeflate = "deflate"
+)
+
+// DefaultCompressMinLength is used when CompressConfig.MinLength is 0
+const DefaultCompressMinLength = 1024
+
+// DefaultCompressMaxDecompressedSize is used when CompressConfig.MaxDecompressedSize is 0
+const DefaultCompressMaxDecompressedSize = 32 << 20
+
+// DefaultCompressExcludedContentTypes are the already-compressed types not compressed again, matched by prefix
+var DefaultCompressExcludedContentTypes = []string{
+	"image/png", "image/jpeg", "image/gif", "image/webp", "image/avif",
+	"video/", "audio/", "font/woff",
+	"application/zip", "application/gzip", "application/x-gzip", "application/zstd",
+	"application/x-7z-compressed", "application/x-rar-compressed", "application/octet-stream",
+}
+
+// CompressConfig defines the config for Compress middleware
+type CompressConfig struct {
+	// Level is the compression level, e.g. gzip.BestSpeed, 0 means gzip.DefaultCompression
+	Level int
+	// MinLength is the minimum size of the response body to be compressed, DefaultCompressMinLength by default
+	MinLength int
+	// ExcludedContentTypes are the content types not compressed, DefaultCompressExcludedContentTypes by default
+	ExcludedContentTypes []string
+	// MaxDecompressedSize limits the decompressed request body, DefaultCompressMaxDecompressedSize by default
+	// reading more fails with *http.MaxBytesError, Bind turns it into 413
+	MaxDecompressedSize int64
+}
+
+// Compress returns a middleware which compresses the response with gzip or deflate
+// and decompresses the gzip request body
+func Compress(conf CompressConfig) HandlerFunc {
+	level := conf.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+		panic("Compress: invalid compression level " + strconv.Itoa(level))
+	}
+	minLength := conf.MinLength
+	if minLength <= 0 {
+		minLength = DefaultCompressMinLength
+	}
+	maxDecompressed := conf.MaxDecompressedSize
+	if maxDecompressed <= 0 {
+		maxDecompressed = DefaultCompressMaxDecompressedSize
+	}
+	excluded := conf.ExcludedContentTypes
+	if excluded == nil {
+		excluded = DefaultCompressExcludedContentTypes
+	}
+	gzipPool := sync.Pool{New: func() interface{} {
+		zw, _ := gzip.NewWriterLevel(io.Discard, level)
+		return zw
+	}}
+	zlibPool := sync.Pool{New: func() interface{} {
+		zw, _ := zlib.NewWriterLevel(io.Discard, level)
+		return zw
+	}}
+
+	return func(c *Context) {
+		if strings.EqualFold(c.Req.Header.Get("Content-Encoding"), encodingGzip) {
+			body, err := gzip.NewReader(c.Req.Body)
+			if err != nil {
+				c.AbortWithStatus(http.StatusBadRequest)
+				return
+			}
+			c.Req.Body = http.MaxBytesReader(originWriter(c.Writer), struct {
+				io.Reader
+				io.Closer
+			}{body, c.Req.Body}, maxDecompressed)
+			c.Req.Header.Del("Content-Encoding")
+			c.Req.Header.Del("Content-Length")
+			c.Req.ContentLength = -1
+		}
+
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+		encoding := negotiateEncoding(c.Req.Header.Get("Accept-Encoding"))
+		if encoding == "" || c.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		w := &compressWriter{ResponseWriter: c.Writer, encoding: encoding, minLength: minLength, excluded: excluded}
+		w.newCompressor = func() compressor {
+			if encoding == encodingGzip {
+				zw := gzipPool.Get().(*gzip.Writer)
+				zw.Reset(w.ResponseWriter)
+				return zw
+			}
+			zw := zlibPool.Get().(*zlib.Writer)
+			zw.Reset(w.ResponseWriter)
+			return zw
+		}
+		c.Writer = w
+		defer func() {
+			w.close()
+			switch zw := w.compressor.(type) {
+			case *gzip.Writer:
+				gzipPool.Put(zw)
+			case *zlib.Writer:
+				zlibPool.Put(zw)
+			}
+			c.Writer = w.ResponseWriter
+		}()
+		c.Next()
+	}
+}
+
+// negotiateEncoding returns the preferred encoding of Accept-Encoding, gzip wins a tie
+// "" is returned when neither gzip nor deflate is acceptable
+// e.g. "deflate, gzip;q=0.8" -> deflate, "*;q=0.5, gzip;q=0" -> deflate
+func negotiateEncoding(acceptEncoding string) string {
+	qs := map[string]float64{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			qs[name] = q
+		}
+	}
+	best, bestQ := "", 0.0
+	for _, encoding := range []string{encodingGzip, encodingDeflate} {
+		q, ok := qs[encoding]
+		if !ok {
+			q, ok = qs["*"]
+		}
+		if ok && q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
+
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// compressWriter buffers the first minLength bytes to decide whether to compress the response
+type compressWriter struct {
+	ResponseWriter
+	encoding      string
+	minLength     int
+	excluded      []string
+	newCompressor func() compressor
+
+	buf        []byte
+	decided    bool
+	compressor compressor // nil when the response is not compressed
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		if len(w.buf)+len(data) < w.minLength {
+			w.buf = append(w.buf, data...)
+			return len(data), nil
+		}
+		if err := w.decide(w.shouldCompress(data)); err != nil {
+			return 0, err
+		}
+	}
+	if w.compressor != nil {
+		return w.compressor.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Unwrap returns the wrapped ResponseWriter, used by http.ResponseController
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Written returns true when the body is buffered, so that the other middlewares won't write the response again
+func (w *compressWriter) Written() bool {
+	return len(w.buf) > 0 || w.ResponseWriter.Written()
+}
+
+// WriteHeaderNow sends the header without compression when nothing is buffered
+func (w *compressWriter) WriteHeaderNow() {
+	if !w.decided {
+		_ = w.decide(w.shouldCompress(nil))
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Flush decides whether to compress immediately, the compressed data is flushed as well
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		_ = w.decide(w.shouldCompress(nil))
+	}
+	if w.compressor != nil {
+		_ = w.compressor.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// shouldCompress judges whether the response should be compressed, data is the pending bytes to be written
+func (w *compressWriter) shouldCompress(data []byte) bool {
+	switch status := w.Status(); {
+	case status < http.StatusOK, status == http.StatusNoContent, status == http.StatusNotModified,
+		status == http.StatusPartialContent:
+		return false
+	}
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		sniff := append(w.buf[:len(w.buf):len(w.buf)], data...)
+		if len(sniff) == 0 {
+			return false
+		}
+		if len(sniff) > 512 {
+			sniff = sniff[:512]
+		}
+		// the compressed body can't be sniffed by net/http
+		contentType = http.DetectContentType(sniff)
+		header.Set("Content-Type", contentType)
+	}
+	contentType = strings.ToLower(contentType)
+	for _, t := range w.excluded {
+		if strings.HasPrefix(contentType, t) {
+			return false
+		}
+	}
+	return true
+}
+
+// decide sends the header and the buffered bytes
+func (w *compressWriter) decide(compress bool) error {
+	w.decided = true
+	if compress {
+		header := w.Header()
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+		w.compressor = w.newCompressor()
+	}
+	if len(w.buf) == 0 {
+		return nil
+	}
+	buf := w.buf
+	w.buf = nil
+	var err error
+	if w.compressor != nil {
+		_, err = w.compressor.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// close writes the buffered bytes uncompressed, or finishes the compressed stream
+func (w *compressWriter) close() {
+	if !w.decided {
+		_ = w.decide(false)
+	}
+	if w.compressor != nil {
+		_ = w.compressor.Close()
+	}
+}
@@ -0,0 +1,134 @@
+package hint
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := map[string]string{
+		"":                        "",
+		"gzip, deflate, br":       encodingGzip,
+		"deflate, gzip;q=0.8":     encodingDeflate,
+		"*;q=0.5, gzip;q=0":       encodingDeflate,
+		"identity":                "",
+		"GZIP;q=0.1, deflate;q=0": encodingGzip,
+	}
+	for accept, want := range tests {
+		if got := negotiateEncoding(accept); got != want {
+			t.Errorf("%q: expect %q, got %q", accept, want, got)
+		}
+	}
+}
+
+func TestCompress(t *testing.T) {
+	large := strings.Repeat("hint ", 1000)
+	e := New()
+	e.Use(Compress(CompressConfig{MinLength: 100}))
+	e.GET("/large", func(c *Context) { c.JSON(http.StatusOK, H{"text": large}) })
+	e.GET("/small", func(c *Context) { c.String(http.StatusOK, "small") })
+	e.GET("/image", func(c *Context) {
+		c.SetHeader("Content-Type", "image/png")
+		c.Data(http.StatusOK, bytes.Repeat([]byte{0}, 1000))
+	})
+	e.POST("/echo", func(c *Context) {
+		body, _ := c.GetRawData()
+		c.Data(http.StatusOK, body)
+	})
+
+	request := func(method, path, accept string, body io.Reader) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, body)
+		req.Header.Set("Accept-Encoding", accept)
+		if body != nil {
+			req.Header.Set("Content-Encoding", "gzip")
+		}
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request(http.MethodGet, "/large", "gzip", nil)
+	if w.Header().Get("Content-Encoding") != "gzip" || w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("expect gzip response, got %v", w.Header())
+	}
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, _ := io.ReadAll(zr); !strings.Contains(string(body), large) {
+		t.Fatalf("unexpected decompressed body %q", body)
+	}
+
+	w = request(http.MethodGet, "/large", "deflate", nil)
+	if w.Header().Get("Content-Encoding") != "deflate" {
+		t.Fatalf("expect deflate response, got %v", w.Header())
+	}
+	fr, err := zlib.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("deflate response is not in zlib format: %v", err)
+	}
+	if body, _ := io.ReadAll(fr); !strings.Contains(string(body), large) {
+		t.Fatalf("unexpected inflated body %q", body)
+	}
+
+	for _, path := range []string{"/small", "/image"} {
+		if w = request(http.MethodGet, path, "gzip", nil); w.Header().Get("Content-Encoding") != "" {
+			t.Errorf("%s: expect uncompressed response, got %v", path, w.Header())
+		}
+	}
+	if w = request(http.MethodGet, "/small", "gzip", nil); w.Body.String() != "small" {
+		t.Fatalf("unexpected body %q", w.Body.String())
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write([]byte("compressed request"))
+	_ = zw.Close()
+	if w = request(http.MethodPost, "/echo", "", &buf); w.Body.String() != "compressed request" {
+		t.Fatalf("request body is not decompressed, got %q", w.Body.String())
+	}
+	if w = request(http.MethodPost, "/echo", "", strings.NewReader("plain")); w.Code != http.StatusBadRequest {
+		t.Fatalf("expect 400 for invalid gzip body, got %d", w.Code)
+	}
+}
+
+func TestCompressMaxDecompressedSize(t *testing.T) {
+	e := New()
+	e.Use(Compress(CompressConfig{MaxDecompressedSize: 1 << 20}))
+	e.POST("/bind", func(c *Context) {
+		var obj struct {
+			Name string `json:"name"`
+		}
+		if c.Bind(&obj) != nil {
+			return
+		}
+		c.String(http.StatusOK, obj.Name)
+	})
+	send := func(body []byte) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, _ = zw.Write(body)
+		_ = zw.Close()
+		req := httptest.NewRequest(http.MethodPost, "/bind", &buf)
+		req.Header.Set("Content-Type", MIMEJSON)
+		req.Header.Set("Content-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := send([]byte(`{"name":"hg"}`)); w.Code != http.StatusOK || w.Body.String() != "hg" {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+	}
+	// 16MB of spaces are compressed to about 16KB
+	bomb := append(bytes.Repeat([]byte(" "), 16<<20), `{"name":"hg"}`...)
+	if w := send(bomb); w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expect 413 for the zip bomb, got %d %q", w.Code, w.Body.String())
+	}
+}
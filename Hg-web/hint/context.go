@@ -1,9 +1,14 @@
 package hint
 
 import (
-	"encoding/json"
-	"fmt"
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"mime/multipart"
 	"net/http"
+	"sync"
+	"time"
 )
 
 // 对Web服务来说，无非是根据请求*http.Request，构造响应http.ResponseWriter。但是这两个对象提供的接口粒度太细。
@@ -19,38 +24,84 @@ type H map[string]interface{}
 
 // Context struct
 type Context struct {
-	// origin info
-	Writer http.ResponseWriter
-	Req    *http.Request
+	// origin info, Writer wraps the origin http.ResponseWriter to track status and size
+	Writer    ResponseWriter
+	Req       *http.Request
+	writermem responseWriter
 	// high freq use request info
 	Path   string
 	Method string
-	Params map[string]string
-	// high freq use response info
+	Params Params
+	// high freq use response info, set by Status
+	//
+	// Deprecated: use Writer.Status(), which also sees the status written directly to Writer
 	StatusCode int
+	// matched pattern of router e.g. /p/:lang/doc
+	fullPath string
 	// middleware
 	handlers []HandlerFunc
 	index    int
+	// Errors is a list of errors attached to all the handlers/middlewares who used this context
+	Errors errorMsgs
 	// engine pointer
 	e *Engine
+	// request body cached by GetRawData, so that the body can be read again
+	bodyBytes []byte
+	// multipart form parsed by MultipartForm, its temporary files are removed when the request finishes
+	multipartForm *multipart.Form
+	// Keys is a key/value pair exclusively for the context of each request
+	// e.g. the authenticated user set by middleware
+	Keys map[string]interface{}
+	mu   sync.RWMutex // protects Keys
 }
 
 func (c *Context) Param(key string) string {
-	value, _ := c.Params[key]
-	return value
+	return c.Params.ByName(key)
+}
+
+// FullPath returns the matched pattern of router, "" when no router matched
+// e.g. /p/:lang/doc
+func (c *Context) FullPath() string {
+	return c.fullPath
 }
 
 // inside func to make a newContext
 func newContext(w http.ResponseWriter, req *http.Request) *Context {
-	return &Context{
-		Writer: w,
+	c := &Context{
 		Req:    req,
 		Path:   req.URL.Path,
 		Method: req.Method,
 		index:  -1,
 	}
+	c.writermem.reset(w)
+	c.Writer = &c.writermem
+	return c
+}
+
+// reset clears all the fields of a pooled Context, Params keeps its capacity to avoid allocation
+func (c *Context) reset(w http.ResponseWriter, req *http.Request) {
+	c.writermem.reset(w)
+	c.Writer = &c.writermem
+	c.Req = req
+	c.Path = req.URL.Path
+	c.Method = req.Method
+	if cap(c.Params) < c.e.router.maxParams {
+		c.Params = make(Params, 0, c.e.router.maxParams)
+	}
+	c.Params = c.Params[:0]
+	c.StatusCode = 0
+	c.fullPath = ""
+	c.handlers = nil
+	c.index = -1
+	c.Errors = c.Errors[:0]
+	c.bodyBytes = nil
+	c.multipartForm = nil
+	c.Keys = nil
 }
 
+// abortIndex is large enough to stop the chain, as the chain never grows so long
+const abortIndex int = math.MaxInt32
+
 func (c *Context) Next() {
 	c.index++
 	s := len(c.handlers)
@@ -59,6 +110,231 @@ func (c *Context) Next() {
 	}
 }
 
+// =========== flow control part start ===========
+
+// Abort prevents pending handlers from being called, the current handler keeps running
+// e.g. an authorization middleware calls Abort when the request is not authorized
+func (c *Context) Abort() {
+	c.index = abortIndex
+}
+
+// IsAborted returns true if the current context was aborted
+func (c *Context) IsAborted() bool {
+	return c.index >= abortIndex
+}
+
+// AbortWithStatus calls Abort and sets the status code, the header is sent at the end of the request
+// so that the body can still be written later, e.g. by ErrorHandler
+func (c *Context) AbortWithStatus(code int) {
+	c.Status(code)
+	c.Abort()
+}
+
+// AbortWithStatusJSON calls Abort and then JSON internally
+func (c *Context) AbortWithStatusJSON(code int, obj interface{}) {
+	c.Abort()
+	c.JSON(code, obj)
+}
+
+// AbortWithError calls AbortWithStatus and Error internally
+// e.g. c.AbortWithError(401, err).SetType(ErrorTypePublic)
+func (c *Context) AbortWithError(code int, err error) *Error {
+	c.AbortWithStatus(code)
+	return c.Error(err)
+}
+
+// Error attaches an error to the current context, the error is pushed to c.Errors
+// errors are private by default, ErrorHandler turns them into a response at the end of the chain
+// it panics when err is nil
+func (c *Context) Error(err error) *Error {
+	if err == nil {
+		panic("err is nil")
+	}
+	var parsedError *Error
+	if !errors.As(err, &parsedError) {
+		parsedError = &Error{
+			Err:  err,
+			Type: ErrorTypePrivate,
+		}
+	}
+	c.Errors = append(c.Errors, parsedError)
+	return parsedError
+}
+
+// =========== flow control part end ===========
+
+// =========== metadata part start ===========
+
+// Set stores a new key/value pair exclusively for this context, it is safe for concurrent use
+// e.g. c.Set("user", user) in an auth middleware, c.MustGet("user") in the handler
+func (c *Context) Set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Keys == nil {
+		c.Keys = make(map[string]interface{})
+	}
+	c.Keys[key] = value
+}
+
+// Get returns the value for the given key, ok is false when the key doesn't exist
+func (c *Context) Get(key string) (value interface{}, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	value, ok = c.Keys[key]
+	return
+}
+
+// MustGet returns the value for the given key, panic when the key doesn't exist
+func (c *Context) MustGet(key string) interface{} {
+	if value, ok := c.Get(key); ok {
+		return value
+	}
+	panic("key \"" + key + "\" does not exist")
+}
+
+// GetString returns the value associated with the key as a string
+func (c *Context) GetString(key string) (s string) {
+	if val, ok := c.Get(key); ok && val != nil {
+		s, _ = val.(string)
+	}
+	return
+}
+
+// GetBool returns the value associated with the key as a boolean
+func (c *Context) GetBool(key string) (b bool) {
+	if val, ok := c.Get(key); ok && val != nil {
+		b, _ = val.(bool)
+	}
+	return
+}
+
+// GetInt returns the value associated with the key as an integer
+func (c *Context) GetInt(key string) (i int) {
+	if val, ok := c.Get(key); ok && val != nil {
+		i, _ = val.(int)
+	}
+	return
+}
+
+// GetInt64 returns the value associated with the key as an integer
+func (c *Context) GetInt64(key string) (i64 int64) {
+	if val, ok := c.Get(key); ok && val != nil {
+		i64, _ = val.(int64)
+	}
+	return
+}
+
+// GetUint returns the value associated with the key as an unsigned integer
+func (c *Context) GetUint(key string) (ui uint) {
+	if val, ok := c.Get(key); ok && val != nil {
+		ui, _ = val.(uint)
+	}
+	return
+}
+
+// GetUint64 returns the value associated with the key as an unsigned integer
+func (c *Context) GetUint64(key string) (ui64 uint64) {
+	if val, ok := c.Get(key); ok && val != nil {
+		ui64, _ = val.(uint64)
+	}
+	return
+}
+
+// GetFloat64 returns the value associated with the key as a float64
+func (c *Context) GetFloat64(key string) (f64 float64) {
+	if val, ok := c.Get(key); ok && val != nil {
+		f64, _ = val.(float64)
+	}
+	return
+}
+
+// GetTime returns the value associated with the key as time
+func (c *Context) GetTime(key string) (t time.Time) {
+	if val, ok := c.Get(key); ok && val != nil {
+		t, _ = val.(time.Time)
+	}
+	return
+}
+
+// GetDuration returns the value associated with the key as a duration
+func (c *Context) GetDuration(key string) (d time.Duration) {
+	if val, ok := c.Get(key); ok && val != nil {
+		d, _ = val.(time.Duration)
+	}
+	return
+}
+
+// GetStringSlice returns the value associated with the key as a slice of strings
+func (c *Context) GetStringSlice(key string) (ss []string) {
+	if val, ok := c.Get(key); ok && val != nil {
+		ss, _ = val.([]string)
+	}
+	return
+}
+
+// GetStringMap returns the value associated with the key as a map of interfaces
+func (c *Context) GetStringMap(key string) (sm map[string]interface{}) {
+	if val, ok := c.Get(key); ok && val != nil {
+		sm, _ = val.(map[string]interface{})
+	}
+	return
+}
+
+// GetStringMapString returns the value associated with the key as a map of strings
+func (c *Context) GetStringMapString(key string) (sms map[string]string) {
+	if val, ok := c.Get(key); ok && val != nil {
+		sms, _ = val.(map[string]string)
+	}
+	return
+}
+
+// =========== metadata part end ===========
+
+// =========== context.Context part start ===========
+
+// Context implements context.Context, so a *Context can be passed to database or hintrpc client calls directly
+// Deadline, Done and Err are delegated to c.Req.Context(), which is cancelled when the client goes away
+// remember the Context is recycled when the request is finished, don't use it after the handler returns
+
+// Deadline returns the deadline of c.Req.Context()
+func (c *Context) Deadline() (deadline time.Time, ok bool) {
+	if c.Req == nil {
+		return
+	}
+	return c.Req.Context().Deadline()
+}
+
+// Done returns the done channel of c.Req.Context()
+func (c *Context) Done() <-chan struct{} {
+	if c.Req == nil {
+		return nil
+	}
+	return c.Req.Context().Done()
+}
+
+// Err returns the error of c.Req.Context()
+func (c *Context) Err() error {
+	if c.Req == nil {
+		return nil
+	}
+	return c.Req.Context().Err()
+}
+
+// Value returns the value stored by Set when key is a string, otherwise the value of c.Req.Context()
+func (c *Context) Value(key interface{}) interface{} {
+	if k, ok := key.(string); ok {
+		if val, exists := c.Get(k); exists {
+			return val
+		}
+	}
+	if c.Req == nil {
+		return nil
+	}
+	return c.Req.Context().Value(key)
+}
+
+// =========== context.Context part end ===========
+
 // =========== request part start ===========
 
 func (c *Context) PostForm(key string) string {
@@ -69,50 +345,245 @@ func (c *Context) Query(key string) string {
 	return c.Req.URL.Query().Get(key)
 }
 
+// GetRawData returns the request body, the body is cached and c.Req.Body is reset
+// so that the body can be read again after binding
+func (c *Context) GetRawData() ([]byte, error) {
+	if c.bodyBytes == nil {
+		if c.Req.Body == nil {
+			return nil, errors.New("invalid request")
+		}
+		body, err := io.ReadAll(c.Req.Body)
+		if err != nil {
+			return nil, err
+		}
+		c.bodyBytes = body
+	}
+	c.Req.Body = io.NopCloser(bytes.NewReader(c.bodyBytes))
+	return c.bodyBytes, nil
+}
+
 // =========== request part end ===========
 
+// =========== binding part start ===========
+
+// Bind checks the method and Content-Type to select a binding automatically
+// e.g. "application/json" -> JSONBinding, "application/xml" -> XMLBinding, GET -> FormBinding
+// it writes a 400 response when the binding fails, use ShouldBind to handle the error by yourself
+func (c *Context) Bind(obj interface{}) error {
+	return c.BindWith(obj, bindingDefault(c.Method, c.Req.Header.Get("Content-Type")))
+}
+
+// BindJSON is a shortcut for c.BindWith(obj, JSONBinding)
+func (c *Context) BindJSON(obj interface{}) error {
+	return c.BindWith(obj, JSONBinding)
+}
+
+// BindXML is a shortcut for c.BindWith(obj, XMLBinding)
+func (c *Context) BindXML(obj interface{}) error {
+	return c.BindWith(obj, XMLBinding)
+}
+
+// BindQuery is a shortcut for c.BindWith(obj, QueryBinding)
+func (c *Context) BindQuery(obj interface{}) error {
+	return c.BindWith(obj, QueryBinding)
+}
+
+// BindHeader is a shortcut for c.BindWith(obj, HeaderBinding)
+func (c *Context) BindHeader(obj interface{}) error {
+	return c.BindWith(obj, HeaderBinding)
+}
+
+// BindUri binds the router params by `uri` tag, it writes a 400 response when the binding fails
+func (c *Context) BindUri(obj interface{}) error {
+	if err := c.ShouldBindUri(obj); err != nil {
+		c.Error(err).SetType(ErrorTypeBind)
+		c.Fail(http.StatusBadRequest, err.Error())
+		return err
+	}
+	return nil
+}
+
+// BindWith binds obj using the specified binding, it writes a 400 response when the binding fails
+// 413 is written instead when the body exceeds the limit of BodyLimit
+func (c *Context) BindWith(obj interface{}, b Binding) error {
+	if err := c.ShouldBindWith(obj, b); err != nil {
+		c.Error(err).SetType(ErrorTypeBind)
+		c.Fail(bindErrorStatus(err), err.Error())
+		return err
+	}
+	return nil
+}
+
+// ShouldBind is the same as Bind but leaves the error to the caller
+func (c *Context) ShouldBind(obj interface{}) error {
+	return c.ShouldBindWith(obj, bindingDefault(c.Method, c.Req.Header.Get("Content-Type")))
+}
+
+// ShouldBindJSON is a shortcut for c.ShouldBindWith(obj, JSONBinding)
+func (c *Context) ShouldBindJSON(obj interface{}) error {
+	return c.ShouldBindWith(obj, JSONBinding)
+}
+
+// ShouldBindXML is a shortcut for c.ShouldBindWith(obj, XMLBinding)
+func (c *Context) ShouldBindXML(obj interface{}) error {
+	return c.ShouldBindWith(obj, XMLBinding)
+}
+
+// ShouldBindQuery is a shortcut for c.ShouldBindWith(obj, QueryBinding)
+func (c *Context) ShouldBindQuery(obj interface{}) error {
+	return c.ShouldBindWith(obj, QueryBinding)
+}
+
+// ShouldBindHeader is a shortcut for c.ShouldBindWith(obj, HeaderBinding)
+func (c *Context) ShouldBindHeader(obj interface{}) error {
+	return c.ShouldBindWith(obj, HeaderBinding)
+}
+
+// ShouldBindUri binds the router params by `uri` tag
+// e.g. /user/:id -> struct { ID int `uri:"id" binding:"required"` }
+func (c *Context) ShouldBindUri(obj interface{}) error {
+	return bindURI(c.Params, obj)
+}
+
+// ShouldBindWith binds obj using the specified binding
+// the body is cached for BindingBody (JSON/XML), so it can be bound again or read by GetRawData
+// multipart forms are parsed with Engine.MaxMultipartMemory before FormBinding and FormMultipartBinding
+func (c *Context) ShouldBindWith(obj interface{}, b Binding) error {
+	if bb, ok := b.(BindingBody); ok {
+		return c.ShouldBindBodyWith(obj, bb)
+	}
+	if b == FormBinding || b == FormMultipartBinding {
+		if _, err := c.MultipartForm(); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+			return err
+		}
+	}
+	return b.Bind(c.Req, obj)
+}
+
+// ShouldBindBodyWith binds obj with the cached request body
+// e.g. try JSONBinding first and then XMLBinding with the same body
+func (c *Context) ShouldBindBodyWith(obj interface{}, bb BindingBody) error {
+	body, err := c.GetRawData()
+	if err != nil {
+		return err
+	}
+	return bb.BindBody(body, obj)
+}
+
+// =========== binding part end ===========
+
 // =========== response part start ===========
 
+// Status sets the status code of the response, the header is sent when the body is written
+// so it can be called more than once before writing the body
 func (c *Context) Status(code int) {
-	c.StatusCode = code
 	c.Writer.WriteHeader(code)
+	c.StatusCode = c.Writer.Status()
 }
 
 func (c *Context) SetHeader(key string, value string) {
 	c.Writer.Header().Set(key, value)
 }
 
-func (c *Context) String(code int, format string, values ...interface{}) {
-	c.SetHeader("Content-Type", "text/plain")
+// Render writes the response headers and calls r.Render to render data
+// the error is recorded as ErrorTypeRender and 500 is returned when nothing has been written
+func (c *Context) Render(code int, r Render) {
 	c.Status(code)
-	c.Writer.Write([]byte(fmt.Sprintf(format, values...)))
+	if !bodyAllowedForStatus(code) {
+		r.WriteContentType(c.Writer)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+	if err := r.Render(c.Writer); err != nil {
+		c.Error(err).SetType(ErrorTypeRender)
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Type")
+			c.Fail(http.StatusInternalServerError, err.Error())
+		}
+	}
+}
+
+// bodyAllowedForStatus reports whether a given response status code permits a body, see RFC 7230, section 3.3
+func bodyAllowedForStatus(status int) bool {
+	switch {
+	case status >= 100 && status <= 199:
+		return false
+	case status == http.StatusNoContent:
+		return false
+	case status == http.StatusNotModified:
+		return false
+	}
+	return true
+}
+
+func (c *Context) String(code int, format string, values ...interface{}) {
+	c.SetHeader("Content-Type", MIMEPlain)
+	c.Render(code, StringRender{Format: format, Data: values})
 }
 
 func (c *Context) JSON(code int, obj interface{}) {
-	c.SetHeader("Content-Type", "application/json")
-	c.Status(code)
-	encoder := json.NewEncoder(c.Writer)
-	if err := encoder.Encode(obj); err != nil {
-		http.Error(c.Writer, err.Error(), 500)
+	c.SetHeader("Content-Type", MIMEJSON)
+	c.Render(code, JSONRender{Data: obj})
+}
+
+// IndentedJSON serializes obj as pretty JSON, it's more CPU and bandwidth consuming, use it for debugging
+func (c *Context) IndentedJSON(code int, obj interface{}) {
+	c.Render(code, IndentedJSONRender{Data: obj})
+}
+
+// PureJSON serializes obj as JSON without replacing HTML characters with their unicode entities
+func (c *Context) PureJSON(code int, obj interface{}) {
+	c.Render(code, PureJSONRender{Data: obj})
+}
+
+// SecureJSON serializes obj as JSON, Engine.SecureJSONPrefix is prepended when obj is an array
+// to prevent JSON hijacking
+func (c *Context) SecureJSON(code int, obj interface{}) {
+	prefix := defaultSecureJSONPrefix
+	if c.e != nil {
+		prefix = c.e.SecureJSONPrefix
+	}
+	c.Render(code, SecureJSONRender{Prefix: prefix, Data: obj})
+}
+
+// JSONP serializes obj as JSON wrapped by the function named by the query param "callback"
+// it's the same as JSON when there is no callback, 400 is returned when the callback is not a valid identifier
+func (c *Context) JSONP(code int, obj interface{}) {
+	callback := c.Query("callback")
+	if callback != "" && !validJSONPCallback(callback) {
+		c.Error(errInvalidJSONPName).SetType(ErrorTypeRender)
+		c.Fail(http.StatusBadRequest, errInvalidJSONPName.Error())
+		return
 	}
+	c.Render(code, JSONPRender{Callback: callback, Data: obj})
+}
+
+func (c *Context) XML(code int, obj interface{}) {
+	c.Render(code, XMLRender{Data: obj})
+}
+
+func (c *Context) YAML(code int, obj interface{}) {
+	c.Render(code, YAMLRender{Data: obj})
+}
+
+// ProtoBuf serializes obj as protobuf, obj must be a proto.Message
+func (c *Context) ProtoBuf(code int, obj interface{}) {
+	c.Render(code, ProtoBufRender{Data: obj})
 }
 
 func (c *Context) Data(code int, data []byte) {
-	c.Status(code)
-	c.Writer.Write(data)
+	c.Render(code, DataRender{Data: data})
 }
 
 func (c *Context) HTML(code int, name string, data interface{}) {
-	c.SetHeader("Content-Type", "text/html")
-	c.Status(code)
-	if err := c.e.htmlTemplates.ExecuteTemplate(c.Writer, name, data); err != nil {
-		c.Fail(500, err.Error())
-	}
+	c.SetHeader("Content-Type", MIMEHTML)
+	c.Render(code, HTMLRender{Template: c.e.htmlTemplates, Name: name, Data: data})
 }
 
 // =========== response part end ===========
 
+// Fail calls Abort and writes the message as JSON
 func (c *Context) Fail(code int, err string) {
-	c.index = len(c.handlers)
+	c.Abort()
 	c.JSON(code, H{"message": err})
 }
@@ -0,0 +1,106 @@
+package hint
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// make sure Context implements context.Context
+var _ context.Context = &Context{}
+
+func TestContextKeys(t *testing.T) {
+	c := newContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	c.Set("user", "hg")
+	c.Set("age", 24)
+	c.Set("timeout", time.Second)
+
+	if c.MustGet("user") != "hg" || c.GetString("user") != "hg" {
+		t.Fatal("user should be hg")
+	}
+	if c.GetInt("age") != 24 || c.GetString("age") != "" {
+		t.Fatal("typed getter returns wrong value")
+	}
+	if c.GetDuration("timeout") != time.Second {
+		t.Fatal("timeout should be 1s")
+	}
+	if _, ok := c.Get("missing"); ok {
+		t.Fatal("missing key should not exist")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("MustGet should panic when key doesn't exist")
+		}
+	}()
+	c.MustGet("missing")
+}
+
+func TestContextKeysConcurrent(t *testing.T) {
+	c := newContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c.Set("k", i)
+			c.GetInt("k")
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestContextAsContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), "trace", "abc"))
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	c := newContext(httptest.NewRecorder(), req)
+	c.Set("user", "hg")
+
+	if c.Value("user") != "hg" || c.Value("trace") != "abc" {
+		t.Fatal("Value should look up Keys first and then the request context")
+	}
+	if c.Err() != nil {
+		t.Fatal("context should not be done yet")
+	}
+	cancel()
+	select {
+	case <-c.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Done should be closed when the request context is cancelled")
+	}
+	if !errors.Is(c.Err(), context.Canceled) {
+		t.Fatalf("expect context.Canceled, got %v", c.Err())
+	}
+
+	// Context can be used as the parent of a derived context
+	child, stop := context.WithTimeout(c, time.Minute)
+	defer stop()
+	if child.Value("user") != "hg" || child.Err() == nil {
+		t.Fatal("derived context should inherit values and cancellation")
+	}
+}
+
+func TestMiddlewarePassesValues(t *testing.T) {
+	e := New()
+	e.Use(func(c *Context) {
+		c.Set("user", "hg")
+		c.Next()
+	})
+	e.GET("/me", func(c *Context) {
+		c.Set("visited", true)
+		c.String(http.StatusOK, c.MustGet("user").(string))
+	})
+	e.GET("/anonymous", func(c *Context) {
+		c.String(http.StatusOK, "%d", len(c.Keys))
+	})
+	if w := performRequest(e, http.MethodGet, "/me"); w.Body.String() != "hg" {
+		t.Fatalf("unexpected body %q", w.Body.String())
+	}
+	// Keys must be cleared when the Context is recycled
+	if w := performRequest(e, http.MethodGet, "/anonymous"); w.Body.String() != "1" {
+		t.Fatalf("Keys of the previous request leaked, got %q", w.Body.String())
+	}
+}
@@ -0,0 +1,134 @@
+package hint
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// cookie 读写: SetCookie 通过 CookieOptions 控制全部属性，值经过 URL 编码，Cookie 读取时解码。
+// 签名 cookie 用 HMAC-SHA256 防篡改，内容对客户端可见；加密 cookie 用 AES-GCM 加密，适合在客户端保存少量私密状态。
+// 两者的编码格式与 CookieCodec 相同，密钥通过 Engine.SetCookieKeys 设置，新密钥放在最前面即可轮换，旧密钥签发的 cookie 仍然可以读取，e.g.
+//
+//	e.SetCookieKeys(newHashKey, newBlockKey, oldHashKey, oldBlockKey)
+//	err := c.SetEncryptedCookie("cart", "sku-1,sku-2", nil)
+//	cart, err := c.EncryptedCookie("cart", 24*time.Hour)
+//
+// 读取时的 maxAge 根据 cookie 内签名的时间戳在服务端检查过期，Max-Age 只是对浏览器的提示，截获的 cookie 仍然可以重放。
+
+// ErrCookieKeys is returned when the keys of signed or encrypted cookies are not set
+var ErrCookieKeys = errors.New("hint: cookie keys are not set, see Engine.SetCookieKeys")
+
+// defaultCookieOptions are used when the options of SetCookie is nil
+var defaultCookieOptions = CookieOptions{Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode}
+
+// SetCookieKeys sets pairs of hash key and block key for signed and encrypted cookies, see NewCookieCodecs
+// signed cookies use all the hash keys, encrypted cookies use the pairs with block key
+// the first pair is used to encode and all of them are tried to decode, so that the keys can be rotated
+func (e *Engine) SetCookieKeys(keyPairs ...[]byte) {
+	e.signedCookieCodecs, e.encryptedCookieCodecs = nil, nil
+	for i := 0; i < len(keyPairs); i += 2 {
+		e.signedCookieCodecs = append(e.signedCookieCodecs, NewCookieCodec(keyPairs[i], nil))
+		if i+1 < len(keyPairs) && keyPairs[i+1] != nil {
+			e.encryptedCookieCodecs = append(e.encryptedCookieCodecs, NewCookieCodec(keyPairs[i], keyPairs[i+1]))
+		}
+	}
+}
+
+// Cookie returns the URL-decoded value of the cookie named name, http.ErrNoCookie when it's absent
+func (c *Context) Cookie(name string) (string, error) {
+	cookie, err := c.Req.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	return url.QueryUnescape(cookie.Value)
+}
+
+// SetCookie adds a Set-Cookie header with the URL-encoded value
+// nil options means Path=/, HttpOnly and SameSite=Lax, an empty Path is "/"
+func (c *Context) SetCookie(name, value string, options *CookieOptions) {
+	c.setCookie(name, url.QueryEscape(value), options)
+}
+
+// DeleteCookie asks the client to delete the cookie, Path and Domain of options must match the cookie
+func (c *Context) DeleteCookie(name string, options *CookieOptions) {
+	o := defaultCookieOptions
+	if options != nil {
+		o = *options
+	}
+	o.MaxAge = -1
+	c.setCookie(name, "", &o)
+}
+
+func (c *Context) setCookie(name, value string, options *CookieOptions) {
+	o := defaultCookieOptions
+	if options != nil {
+		o = *options
+	}
+	if o.Path == "" {
+		o.Path = "/"
+	}
+	http.SetCookie(c.Writer, o.newCookie(name, value))
+}
+
+// SetSignedCookie sets a cookie signed with HMAC, the value is visible to the client but can't be modified
+func (c *Context) SetSignedCookie(name, value string, options *CookieOptions) error {
+	return c.setCodecCookie(c.cookieCodecs(false), name, value, options)
+}
+
+// SignedCookie returns the value of the cookie set by SetSignedCookie
+// ErrCookieInvalid is returned when the cookie has been modified,
+// ErrCookieExpired when it was set more than maxAge ago, 0 means no limit
+func (c *Context) SignedCookie(name string, maxAge time.Duration) (string, error) {
+	return c.codecCookie(c.cookieCodecs(false), name, maxAge)
+}
+
+// SetEncryptedCookie sets a cookie encrypted with AES-GCM, the value is neither visible nor modifiable by the client
+func (c *Context) SetEncryptedCookie(name, value string, options *CookieOptions) error {
+	return c.setCodecCookie(c.cookieCodecs(true), name, value, options)
+}
+
+// EncryptedCookie returns the value of the cookie set by SetEncryptedCookie
+// ErrCookieInvalid is returned when the cookie can't be decrypted with any of the keys,
+// ErrCookieExpired when it was set more than maxAge ago, 0 means no limit
+func (c *Context) EncryptedCookie(name string, maxAge time.Duration) (string, error) {
+	return c.codecCookie(c.cookieCodecs(true), name, maxAge)
+}
+
+func (c *Context) cookieCodecs(encrypted bool) []*CookieCodec {
+	switch {
+	case c.e == nil:
+		return nil
+	case encrypted:
+		return c.e.encryptedCookieCodecs
+	}
+	return c.e.signedCookieCodecs
+}
+
+func (c *Context) setCodecCookie(codecs []*CookieCodec, name, value string, options *CookieOptions) error {
+	if len(codecs) == 0 {
+		return ErrCookieKeys
+	}
+	encoded, err := encodeCookie(codecs, name, []byte(value))
+	if err != nil {
+		return err
+	}
+	c.setCookie(name, encoded, options)
+	return nil
+}
+
+func (c *Context) codecCookie(codecs []*CookieCodec, name string, maxAge time.Duration) (string, error) {
+	if len(codecs) == 0 {
+		return "", ErrCookieKeys
+	}
+	cookie, err := c.Req.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	value, err := decodeCookie(codecs, name, cookie.Value, maxAge)
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
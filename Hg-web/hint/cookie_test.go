@@ -0,0 +1,150 @@
+package hint
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// cookieRequest sends a request with cookies and returns the response
+func cookieRequest(e *Engine, path string, cookies []*http.Cookie) *http.Response {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	return w.Result()
+}
+
+func TestSetCookie(t *testing.T) {
+	e := New()
+	e.GET("/set", func(c *Context) {
+		c.SetCookie("plain", "a b;c", nil)
+		c.SetCookie("opts", "v", &CookieOptions{Domain: "example.com", MaxAge: 60, Secure: true, SameSite: http.SameSiteStrictMode})
+		c.DeleteCookie("old", nil)
+	})
+	e.GET("/get", func(c *Context) {
+		plain, err := c.Cookie("plain")
+		_, missing := c.Cookie("missing")
+		c.String(http.StatusOK, "%s|%v|%v", plain, err, errors.Is(missing, http.ErrNoCookie))
+	})
+
+	resp := cookieRequest(e, "/set", nil)
+	headers := resp.Header.Values("Set-Cookie")
+	want := []string{
+		"plain=a+b%3Bc; Path=/; HttpOnly; SameSite=Lax",
+		"opts=v; Path=/; Domain=example.com; Expires=",
+		"old=; Path=/; Expires=Thu, 01 Jan 1970 00:00:01 GMT; Max-Age=0; HttpOnly; SameSite=Lax",
+	}
+	if len(headers) != len(want) {
+		t.Fatalf("unexpected cookies %q", headers)
+	}
+	for i, h := range headers {
+		if !strings.HasPrefix(h, want[i]) {
+			t.Fatalf("unexpected cookie %q, want %q", h, want[i])
+		}
+	}
+	if !strings.HasSuffix(headers[1], "Max-Age=60; Secure; SameSite=Strict") {
+		t.Fatalf("unexpected cookie %q", headers[1])
+	}
+
+	resp = cookieRequest(e, "/get", resp.Cookies()[:1])
+	if body := readBody(t, resp); body != "a b;c|<nil>|true" {
+		t.Fatalf("unexpected body %q", body)
+	}
+}
+
+func TestSignedAndEncryptedCookie(t *testing.T) {
+	oldHash, oldBlock := bytes.Repeat([]byte("h"), 32), bytes.Repeat([]byte("b"), 32)
+	e := New()
+	e.SetCookieKeys(oldHash, oldBlock)
+	e.GET("/set", func(c *Context) {
+		if err := c.SetSignedCookie("uid", "42", nil); err != nil {
+			t.Error(err)
+		}
+		if err := c.SetEncryptedCookie("cart", "sku-1,sku-2", nil); err != nil {
+			t.Error(err)
+		}
+	})
+	e.GET("/get", func(c *Context) {
+		uid, uidErr := c.SignedCookie("uid", time.Hour)
+		cart, cartErr := c.EncryptedCookie("cart", 0)
+		c.String(http.StatusOK, "%s %v %s %v", uid, uidErr, cart, cartErr)
+	})
+
+	cookies := cookieRequest(e, "/set", nil).Cookies()
+	if len(cookies) != 2 || strings.Contains(cookies[1].Value, "sku") {
+		t.Fatalf("unexpected cookies %v", cookies)
+	}
+
+	// the cookies issued with the old keys are still valid after rotation
+	e.SetCookieKeys(bytes.Repeat([]byte("H"), 32), bytes.Repeat([]byte("B"), 32), oldHash, oldBlock)
+	if body := readBody(t, cookieRequest(e, "/get", cookies)); body != "42 <nil> sku-1,sku-2 <nil>" {
+		t.Fatalf("unexpected body %q", body)
+	}
+
+	// the value can't be moved to another cookie or modified
+	tampered := []*http.Cookie{
+		{Name: "uid", Value: cookies[1].Value},
+		{Name: "cart", Value: cookies[1].Value[:len(cookies[1].Value)-2] + "AA"},
+	}
+	want := " " + ErrCookieInvalid.Error() + "  " + ErrCookieInvalid.Error()
+	if body := readBody(t, cookieRequest(e, "/get", tampered)); body != want {
+		t.Fatalf("unexpected body %q", body)
+	}
+
+	// the old keys are removed
+	e.SetCookieKeys(bytes.Repeat([]byte("H"), 32), bytes.Repeat([]byte("B"), 32))
+	if body := readBody(t, cookieRequest(e, "/get", cookies)); !strings.Contains(body, ErrCookieInvalid.Error()) {
+		t.Fatalf("unexpected body %q", body)
+	}
+}
+
+func TestSignedAndEncryptedCookieExpired(t *testing.T) {
+	e := New()
+	e.SetCookieKeys(bytes.Repeat([]byte("h"), 32), bytes.Repeat([]byte("b"), 32))
+	e.GET("/set", func(c *Context) {
+		_ = c.SetSignedCookie("uid", "42", nil)
+		_ = c.SetEncryptedCookie("cart", "sku-1", nil)
+	})
+	e.GET("/get", func(c *Context) {
+		_, uidErr := c.SignedCookie("uid", time.Millisecond)
+		_, cartErr := c.EncryptedCookie("cart", time.Millisecond)
+		c.String(http.StatusOK, "%v|%v", uidErr, cartErr)
+	})
+
+	cookies := cookieRequest(e, "/set", nil).Cookies()
+	// the timestamp is in seconds, so the cookies are older than 1ms after the sleep
+	time.Sleep(2 * time.Millisecond)
+	want := ErrCookieExpired.Error() + "|" + ErrCookieExpired.Error()
+	if body := readBody(t, cookieRequest(e, "/get", cookies)); body != want {
+		t.Fatalf("unexpected body %q", body)
+	}
+}
+
+func TestCookieKeysRequired(t *testing.T) {
+	e := New()
+	e.SetCookieKeys(bytes.Repeat([]byte("h"), 32))
+	e.GET("/", func(c *Context) {
+		signedErr := c.SetSignedCookie("uid", "42", nil)
+		encryptedErr := c.SetEncryptedCookie("cart", "sku-1", nil)
+		c.String(http.StatusOK, "%v|%v", signedErr, encryptedErr)
+	})
+	if body := readBody(t, cookieRequest(e, "/", nil)); body != "<nil>|"+ErrCookieKeys.Error() {
+		t.Fatalf("unexpected body %q", body)
+	}
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(resp.Body); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
@@ -0,0 +1,147 @@
+package hint
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 浏览器跨域请求(CORS)分为简单请求和预检请求:
+// 预检请求是带有 Access-Control-Request-Method 头的 OPTIONS 请求，浏览器根据响应决定是否发送真正的请求。
+// 没有注册 OPTIONS 路由时，router 也会执行分组的中间件(见 router.handle)，因此通过 Use 添加的 CORS 中间件可以直接应答预检请求。
+// e.g.
+//
+//	e.Use(hint.CORS(hint.CORSConfig{
+//		AllowOrigins:     []string{"https://example.com", "https://*.example.com"},
+//		AllowHeaders:     []string{"Authorization", "Content-Type"},
+//		AllowCredentials: true,
+//		MaxAge:           12 * time.Hour,
+//	}))
+
+// CORSConfig defines the config for CORS middleware
+type CORSConfig struct {
+	// AllowOrigins is a list of origins a cross-domain request can be executed from
+	// "*" allows all origins, a wildcard subdomain like https://*.example.com is supported
+	AllowOrigins []string
+	// AllowOriginFunc is a custom function to validate the origin, it's checked after AllowOrigins
+	AllowOriginFunc func(origin string) bool
+	// AllowMethods is a list of methods allowed for preflight requests, GET POST PUT PATCH DELETE HEAD by default
+	AllowMethods []string
+	// AllowHeaders is a list of non simple headers allowed for preflight requests
+	// the Access-Control-Request-Headers of the request is allowed when it's empty
+	AllowHeaders []string
+	// ExposeHeaders indicates which headers are safe to expose to the client
+	ExposeHeaders []string
+	// AllowCredentials indicates whether the request can include cookies, HTTP authentication or client certificates
+	AllowCredentials bool
+	// MaxAge indicates how long the results of a preflight request can be cached, 0 means no Access-Control-Max-Age
+	MaxAge time.Duration
+}
+
+var defaultCORSMethods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodHead,
+}
+
+// CORS returns a middleware which handles cross-origin requests
+// requests from disallowed origins are aborted with 403, preflight requests are answered with 204
+func CORS(conf CORSConfig) HandlerFunc {
+	if len(conf.AllowOrigins) == 0 && conf.AllowOriginFunc == nil {
+		panic("CORS: AllowOrigins or AllowOriginFunc is required")
+	}
+	allowAll := false
+	exact := make(map[string]struct{}, len(conf.AllowOrigins))
+	var wildcards [][2]string
+	for _, origin := range conf.AllowOrigins {
+		origin = strings.ToLower(origin)
+		switch n := strings.Count(origin, "*"); {
+		case origin == "*":
+			allowAll = true
+		case n == 0:
+			exact[origin] = struct{}{}
+		case n == 1:
+			i := strings.IndexByte(origin, '*')
+			wildcards = append(wildcards, [2]string{origin[:i], origin[i+1:]})
+		default:
+			panic("CORS: only one wildcard is allowed in origin " + origin)
+		}
+	}
+	allowOrigin := func(origin string) bool {
+		if allowAll {
+			return true
+		}
+		lower := strings.ToLower(origin)
+		if _, ok := exact[lower]; ok {
+			return true
+		}
+		for _, w := range wildcards {
+			if len(lower) > len(w[0])+len(w[1]) && strings.HasPrefix(lower, w[0]) && strings.HasSuffix(lower, w[1]) {
+				return true
+			}
+		}
+		return conf.AllowOriginFunc != nil && conf.AllowOriginFunc(origin)
+	}
+
+	methods := conf.AllowMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	allowMethods := strings.ToUpper(strings.Join(methods, ", "))
+	allowHeaders := strings.Join(conf.AllowHeaders, ", ")
+	exposeHeaders := strings.Join(conf.ExposeHeaders, ", ")
+	maxAge := ""
+	if conf.MaxAge > 0 {
+		maxAge = strconv.FormatInt(int64(conf.MaxAge/time.Second), 10)
+	}
+	// the origin is echoed instead of "*" when credentials are allowed, as the browser requires
+	anyOrigin := allowAll && !conf.AllowCredentials
+
+	return func(c *Context) {
+		origin := c.Req.Header.Get("Origin")
+		if origin == "" {
+			// not a cross-origin request
+			c.Next()
+			return
+		}
+		header := c.Writer.Header()
+		preflight := c.Method == http.MethodOptions && c.Req.Header.Get("Access-Control-Request-Method") != ""
+		if !anyOrigin {
+			header.Add("Vary", "Origin")
+		}
+		if preflight {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+		}
+		if !allowOrigin(origin) {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		if anyOrigin {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+		if conf.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+		if !preflight {
+			if exposeHeaders != "" {
+				header.Set("Access-Control-Expose-Headers", exposeHeaders)
+			}
+			c.Next()
+			return
+		}
+
+		header.Set("Access-Control-Allow-Methods", allowMethods)
+		if allowHeaders != "" {
+			header.Set("Access-Control-Allow-Headers", allowHeaders)
+		} else if reqHeaders := c.Req.Header.Get("Access-Control-Request-Headers"); reqHeaders != "" {
+			header.Set("Access-Control-Allow-Headers", reqHeaders)
+		}
+		if maxAge != "" {
+			header.Set("Access-Control-Max-Age", maxAge)
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
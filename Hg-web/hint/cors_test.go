@@ -0,0 +1,68 @@
+package hint
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORS(t *testing.T) {
+	e := New()
+	e.Use(CORS(CORSConfig{
+		AllowOrigins:     []string{"https://example.com", "https://*.example.org"},
+		AllowOriginFunc:  func(origin string) bool { return origin == "http://localhost:3000" },
+		AllowHeaders:     []string{"Authorization", "Content-Type"},
+		ExposeHeaders:    []string{"X-Total"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	}))
+	e.GET("/users", func(c *Context) { c.String(http.StatusOK, "users") })
+
+	request := func(method, origin, reqMethod string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/users", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if reqMethod != "" {
+			req.Header.Set("Access-Control-Request-Method", reqMethod)
+		}
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	// preflight without OPTIONS router
+	w := request(http.MethodOptions, "https://api.example.org", http.MethodGet)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("preflight: expect 204, got %d", w.Code)
+	}
+	for k, v := range map[string]string{
+		"Access-Control-Allow-Origin":      "https://api.example.org",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Allow-Headers":     "Authorization, Content-Type",
+		"Access-Control-Allow-Methods":     "GET, POST, PUT, PATCH, DELETE, HEAD",
+		"Access-Control-Max-Age":           "3600",
+	} {
+		if got := w.Header().Get(k); got != v {
+			t.Errorf("preflight: expect %s %q, got %q", k, v, got)
+		}
+	}
+
+	w = request(http.MethodGet, "http://localhost:3000", "")
+	if w.Code != http.StatusOK || w.Header().Get("Access-Control-Expose-Headers") != "X-Total" ||
+		w.Header().Get("Access-Control-Allow-Origin") != "http://localhost:3000" {
+		t.Fatalf("actual request: unexpected response %d %v", w.Code, w.Header())
+	}
+
+	// the wildcard requires a non-empty subdomain
+	for _, origin := range []string{"https://evil.com", "https://.example.org", "http://api.example.org"} {
+		if w = request(http.MethodGet, origin, ""); w.Code != http.StatusForbidden {
+			t.Errorf("%s: expect 403, got %d", origin, w.Code)
+		}
+	}
+
+	if w = request(http.MethodGet, "", ""); w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("same-origin request: unexpected response %d %v", w.Code, w.Header())
+	}
+}
@@ -0,0 +1,253 @@
+package hint
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// CSRF(跨站请求伪造)防护，支持两种模式:
+// double-submit cookie(默认): token 保存在 cookie 中，表单或请求头提交的 token 必须与 cookie 中的一致，配置 Key 后 cookie 会被签名。
+// synchronizer token(UseSession): token 保存在会话中，需要先使用 Sessions 中间件。
+// 输出到页面的 token 每次请求都用随机数掩码(XOR)，压缩后的响应不会泄露 token(BREACH)。
+// GET/HEAD/OPTIONS/TRACE 请求不校验，其余请求必须通过表单字段或 X-CSRF-Token 请求头提交 token，e.g.
+//
+//	conf := hint.CSRFConfig{Key: hashKey}
+//	e.SetFuncMap(hint.CSRFFuncMap(conf))
+//	e.LoadHTMLGlob("templates/*")
+//	e.Use(hint.CSRF(conf))
+//	e.GET("/form", func(c *hint.Context) {
+//		c.HTML(http.StatusOK, "form.tmpl", hint.H{"csrf": c.GetString(hint.CSRFTokenKey)})
+//	})
+//
+// form.tmpl 中: <form method="post">{{ csrfField .csrf }}...</form>
+
+// CSRFTokenKey is the key of the masked token in Context.Keys, it's rendered into forms or sent by the header
+const CSRFTokenKey = "csrf_token"
+
+const (
+	csrfTokenLength   = 32
+	csrfSessionKey    = "_csrf_token"
+	defaultCSRFName   = "_csrf"
+	defaultCSRFHeader = "X-CSRF-Token"
+	csrfFuncName      = "csrfField"
+)
+
+var (
+	ErrCSRFToken  = errors.New("csrf: token is missing or invalid")
+	ErrCSRFOrigin = errors.New("csrf: origin is not allowed")
+)
+
+// CSRFConfig defines the config for CSRF middleware
+type CSRFConfig struct {
+	// UseSession stores the token in the session instead of a cookie, the Sessions middleware is required
+	UseSession bool
+	// Key signs the token cookie, it's recommended for the double-submit cookie mode
+	Key []byte
+	// CookieName is the name of the token cookie, "_csrf" by default
+	CookieName string
+	// CookieOptions are the attributes of the token cookie, Path=/, HttpOnly and SameSite=Lax by default
+	CookieOptions *CookieOptions
+	// FieldName is the name of the hidden form field, "_csrf" by default
+	FieldName string
+	// HeaderName is the name of the request header carrying the token, "X-CSRF-Token" by default
+	HeaderName string
+	// CheckOrigin rejects the unsafe requests whose Origin (or Referer when Origin is absent)
+	// is neither the host of the request nor one of TrustedOrigins
+	CheckOrigin    bool
+	TrustedOrigins []string
+	// ExemptPaths are the router patterns (e.g. /webhook/:id) or paths not checked
+	ExemptPaths []string
+	// ExemptFunc returns true when the request is not checked
+	ExemptFunc func(c *Context) bool
+}
+
+func (conf *CSRFConfig) fieldName() string {
+	if conf.FieldName == "" {
+		return defaultCSRFName
+	}
+	return conf.FieldName
+}
+
+// CSRFFuncMap returns the template function csrfField, which renders the hidden token field
+// e.g. {{ csrfField .csrf }} -> <input type="hidden" name="_csrf" value="...">
+// merge it with other functions before calling SetFuncMap
+func CSRFFuncMap(conf CSRFConfig) template.FuncMap {
+	name := template.HTMLEscapeString(conf.fieldName())
+	return template.FuncMap{
+		csrfFuncName: func(token string) template.HTML {
+			return template.HTML(`<input type="hidden" name="` + name + `" value="` + template.HTMLEscapeString(token) + `">`)
+		},
+	}
+}
+
+// CSRF returns a middleware which protects unsafe requests from cross-site request forgery
+// the masked token is set with CSRFTokenKey, invalid requests are aborted with 403
+func CSRF(conf CSRFConfig) HandlerFunc {
+	cookieName := conf.CookieName
+	if cookieName == "" {
+		cookieName = defaultCSRFName
+	}
+	options := CookieOptions{Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode}
+	if conf.CookieOptions != nil {
+		options = *conf.CookieOptions
+	}
+	fieldName := conf.fieldName()
+	headerName := conf.HeaderName
+	if headerName == "" {
+		headerName = defaultCSRFHeader
+	}
+	var codecs []*CookieCodec
+	if conf.Key != nil {
+		codecs = NewCookieCodecs(conf.Key)
+	}
+	exempt := make(map[string]struct{}, len(conf.ExemptPaths))
+	for _, p := range conf.ExemptPaths {
+		exempt[p] = struct{}{}
+	}
+	trusted := make(map[string]struct{}, len(conf.TrustedOrigins))
+	for _, origin := range conf.TrustedOrigins {
+		trusted[strings.ToLower(strings.TrimSuffix(origin, "/"))] = struct{}{}
+	}
+
+	// loadToken returns the real token of the client, a new token is issued when it's absent or invalid
+	loadToken := func(c *Context) ([]byte, error) {
+		if conf.UseSession {
+			s := c.Session()
+			if token, ok := s.Get(csrfSessionKey).([]byte); ok && len(token) == csrfTokenLength {
+				return token, nil
+			}
+			token, err := newCSRFToken()
+			if err != nil {
+				return nil, err
+			}
+			s.Set(csrfSessionKey, token)
+			return token, s.Save()
+		}
+		if cookie, err := c.Req.Cookie(cookieName); err == nil {
+			var token []byte
+			if codecs != nil {
+				token, err = decodeCookie(codecs, cookieName, cookie.Value, 0)
+			} else {
+				token, err = base64.RawURLEncoding.DecodeString(cookie.Value)
+			}
+			if err == nil && len(token) == csrfTokenLength {
+				return token, nil
+			}
+		}
+		token, err := newCSRFToken()
+		if err != nil {
+			return nil, err
+		}
+		value := base64.RawURLEncoding.EncodeToString(token)
+		if codecs != nil {
+			if value, err = encodeCookie(codecs, cookieName, token); err != nil {
+				return nil, err
+			}
+		}
+		http.SetCookie(c.Writer, options.newCookie(cookieName, value))
+		return token, nil
+	}
+
+	return func(c *Context) {
+		if _, ok := exempt[c.FullPath()]; ok {
+			c.Next()
+			return
+		}
+		if _, ok := exempt[c.Path]; ok || (conf.ExemptFunc != nil && conf.ExemptFunc(c)) {
+			c.Next()
+			return
+		}
+		c.Writer.Header().Add("Vary", "Cookie")
+		token, err := loadToken(c)
+		if err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		masked, err := maskCSRFToken(token)
+		if err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		c.Set(CSRFTokenKey, masked)
+
+		switch c.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+			c.Next()
+			return
+		}
+		if conf.CheckOrigin && !sameOrigin(c.Req, trusted) {
+			c.AbortWithError(http.StatusForbidden, ErrCSRFOrigin).SetMeta(H{"client_ip": c.ClientIP()})
+			return
+		}
+		submitted := c.Req.Header.Get(headerName)
+		if submitted == "" {
+			submitted = c.Req.PostFormValue(fieldName)
+		}
+		if !validCSRFToken(token, submitted) {
+			c.AbortWithError(http.StatusForbidden, ErrCSRFToken).SetMeta(H{"client_ip": c.ClientIP()})
+			return
+		}
+		c.Next()
+	}
+}
+
+// sameOrigin checks Origin, or Referer when Origin is absent, against the host of the request and the trusted origins
+// requests without both of them are allowed, e.g. requests from non-browser clients
+func sameOrigin(req *http.Request, trusted map[string]struct{}) bool {
+	origin := req.Header.Get("Origin")
+	if origin == "" || origin == "null" {
+		origin = req.Referer()
+	}
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if strings.EqualFold(u.Host, req.Host) {
+		return true
+	}
+	_, ok := trusted[strings.ToLower(u.Scheme+"://"+u.Host)]
+	return ok
+}
+
+func newCSRFToken() ([]byte, error) {
+	token := make([]byte, csrfTokenLength)
+	if _, err := rand.Read(token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// maskCSRFToken returns base64(pad | pad XOR token) with a random one-time pad
+func maskCSRFToken(token []byte) (string, error) {
+	masked := make([]byte, 2*csrfTokenLength)
+	pad := masked[:csrfTokenLength]
+	if _, err := rand.Read(pad); err != nil {
+		return "", err
+	}
+	for i := range token {
+		masked[csrfTokenLength+i] = pad[i] ^ token[i]
+	}
+	return base64.RawURLEncoding.EncodeToString(masked), nil
+}
+
+// validCSRFToken unmasks the submitted token and compares it with token in constant time
+func validCSRFToken(token []byte, submitted string) bool {
+	masked, err := base64.RawURLEncoding.DecodeString(submitted)
+	if err != nil || len(masked) != 2*csrfTokenLength {
+		return false
+	}
+	unmasked := make([]byte, csrfTokenLength)
+	for i := range unmasked {
+		unmasked[i] = masked[i] ^ masked[csrfTokenLength+i]
+	}
+	return subtle.ConstantTimeCompare(unmasked, token) == 1
+}
@@ -0,0 +1,125 @@
+package hint
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestCSRF(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "form.tmpl"), []byte(`<form>{{ csrfField .csrf }}</form>`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	conf := CSRFConfig{
+		Key:            []byte("0123456789abcdef0123456789abcdef"),
+		CheckOrigin:    true,
+		TrustedOrigins: []string{"https://trusted.example.com"},
+		ExemptPaths:    []string{"/webhook/:id"},
+	}
+	e := New()
+	e.SetFuncMap(CSRFFuncMap(conf))
+	e.LoadHTMLGlob(filepath.Join(dir, "*"))
+	e.Use(CSRF(conf))
+	e.GET("/form", func(c *Context) {
+		c.HTML(http.StatusOK, "form.tmpl", H{"csrf": c.GetString(CSRFTokenKey)})
+	})
+	ok := func(c *Context) { c.String(http.StatusOK, "ok") }
+	e.POST("/form", ok)
+	e.POST("/webhook/:id", ok)
+
+	w := performRequest(e, http.MethodGet, "/form")
+	field := regexp.MustCompile(`<input type="hidden" name="_csrf" value="([^"]+)">`).FindStringSubmatch(w.Body.String())
+	if field == nil {
+		t.Fatalf("hidden field is not rendered: %q", w.Body.String())
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || !cookies[0].HttpOnly {
+		t.Fatalf("unexpected token cookie %v", cookies)
+	}
+
+	post := func(token, header, origin string, withCookie bool) int {
+		form := url.Values{}
+		if token != "" {
+			form.Set("_csrf", token)
+		}
+		req := httptest.NewRequest(http.MethodPost, "/form", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", MIMEPOSTForm)
+		if header != "" {
+			req.Header.Set("X-CSRF-Token", header)
+		}
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if withCookie {
+			req.AddCookie(cookies[0])
+		}
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		header     string
+		origin     string
+		withCookie bool
+		code       int
+	}{
+		{"form field", field[1], "", "", true, http.StatusOK},
+		{"header", "", field[1], "", true, http.StatusOK},
+		{"same origin", field[1], "", "http://example.com", true, http.StatusOK},
+		{"trusted origin", field[1], "", "https://trusted.example.com", true, http.StatusOK},
+		{"cross origin", field[1], "", "https://evil.com", true, http.StatusForbidden},
+		{"missing token", "", "", "", true, http.StatusForbidden},
+		{"invalid token", "invalid", "", "", true, http.StatusForbidden},
+		{"missing cookie", field[1], "", "", false, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		if code := post(tt.token, tt.header, tt.origin, tt.withCookie); code != tt.code {
+			t.Errorf("%s: expect %d, got %d", tt.name, tt.code, code)
+		}
+	}
+
+	if w = performRequest(e, http.MethodPost, "/webhook/1"); w.Code != http.StatusOK {
+		t.Fatalf("exempt route is checked, got %d", w.Code)
+	}
+}
+
+func TestCSRFSession(t *testing.T) {
+	e := New()
+	e.Use(Sessions("hint_session", NewCookieStore([]byte("0123456789abcdef0123456789abcdef"))))
+	e.Use(CSRF(CSRFConfig{UseSession: true}))
+	e.GET("/token", func(c *Context) { c.String(http.StatusOK, c.GetString(CSRFTokenKey)) })
+	e.POST("/submit", func(c *Context) { c.String(http.StatusOK, "ok") })
+
+	client := &sessionClient{e: e, cookies: map[string]*http.Cookie{}}
+	token := client.do(http.MethodGet, "/token").Body.String()
+	// tokens are masked differently per request
+	if again := client.do(http.MethodGet, "/token").Body.String(); again == token {
+		t.Fatal("token is not masked")
+	}
+	if _, ok := client.cookies[defaultCSRFName]; ok {
+		t.Fatal("token cookie should not be set in session mode")
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/submit", nil)
+	req.Header.Set("X-CSRF-Token", token)
+	for _, cookie := range client.cookies {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expect 200, got %d", w.Code)
+	}
+	if w = client.do(http.MethodPost, "/submit"); w.Code != http.StatusForbidden {
+		t.Fatalf("expect 403 without token, got %d", w.Code)
+	}
+}
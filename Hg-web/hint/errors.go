@@ -0,0 +1,168 @@
+package hint
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// 请求处理过程中产生的错误通过 Context.Error 收集起来，而不是在每个 handler 中各自写响应。
+// 错误按类型区分: public 错误的信息可以返回给客户端，private 错误只记录日志，bind 错误来自请求绑定。
+// ErrorHandler 中间件在处理链结束后统一把收集到的错误转换为响应。
+
+// ErrorType is an unsigned 64-bit bit flag of the error kinds, types can be combined with "|"
+type ErrorType uint64
+
+const (
+	// ErrorTypeBind is used when Context.Bind() fails
+	ErrorTypeBind ErrorType = 1 << 63
+	// ErrorTypeRender is used when Context.Render() fails
+	ErrorTypeRender ErrorType = 1 << 62
+	// ErrorTypePrivate indicates a private error, the message is not exposed to the client
+	ErrorTypePrivate ErrorType = 1 << 0
+	// ErrorTypePublic indicates a public error, the message is exposed to the client
+	ErrorTypePublic ErrorType = 1 << 1
+	// ErrorTypeAny indicates any other error
+	ErrorTypeAny ErrorType = 1<<64 - 1
+)
+
+// Error represents an error's specification
+type Error struct {
+	Err  error
+	Type ErrorType
+	Meta interface{}
+}
+
+type errorMsgs []*Error
+
+var _ error = (*Error)(nil)
+
+// SetType sets the error's type
+func (msg *Error) SetType(flags ErrorType) *Error {
+	msg.Type = flags
+	return msg
+}
+
+// SetMeta sets the error's meta data
+func (msg *Error) SetMeta(data interface{}) *Error {
+	msg.Meta = data
+	return msg
+}
+
+// Error implements the error interface
+func (msg *Error) Error() string {
+	return msg.Err.Error()
+}
+
+// Unwrap returns the wrapped error, to allow interoperability with errors.Is(), errors.As()
+func (msg *Error) Unwrap() error {
+	return msg.Err
+}
+
+// IsType judges one error type
+func (msg *Error) IsType(flags ErrorType) bool {
+	return (msg.Type & flags) > 0
+}
+
+// JSON creates a properly formatted JSON
+func (msg *Error) JSON() H {
+	h := H{"message": msg.Error()}
+	switch {
+	case msg.IsType(ErrorTypeBind):
+		h["type"] = "bind"
+		if errs, ok := msg.Err.(ValidationErrors); ok {
+			h["fields"] = errs
+		}
+	case msg.IsType(ErrorTypePublic):
+		h["type"] = "public"
+	}
+	if msg.Meta != nil {
+		h["meta"] = msg.Meta
+	}
+	return h
+}
+
+// ByType returns a readonly copy filtered by type
+// e.g. c.Errors.ByType(ErrorTypePublic) returns a slice of errors with type=ErrorTypePublic
+func (a errorMsgs) ByType(typ ErrorType) errorMsgs {
+	if len(a) == 0 {
+		return nil
+	}
+	if typ == ErrorTypeAny {
+		return a
+	}
+	var result errorMsgs
+	for _, msg := range a {
+		if msg.IsType(typ) {
+			result = append(result, msg)
+		}
+	}
+	return result
+}
+
+// Last returns the last error in the slice, nil when the slice is empty
+func (a errorMsgs) Last() *Error {
+	if length := len(a); length > 0 {
+		return a[length-1]
+	}
+	return nil
+}
+
+// Errors returns an array with all the error messages
+func (a errorMsgs) Errors() []string {
+	if len(a) == 0 {
+		return nil
+	}
+	errorStrings := make([]string, len(a))
+	for i, err := range a {
+		errorStrings[i] = err.Error()
+	}
+	return errorStrings
+}
+
+func (a errorMsgs) String() string {
+	if len(a) == 0 {
+		return ""
+	}
+	var buffer strings.Builder
+	for i, msg := range a {
+		fmt.Fprintf(&buffer, "Error #%02d: %s\n", i+1, msg.Err)
+		if msg.Meta != nil {
+			fmt.Fprintf(&buffer, "     Meta: %v\n", msg.Meta)
+		}
+	}
+	return buffer.String()
+}
+
+// ErrorHandler is a terminal middleware which turns the errors collected by Context.Error into a response
+// it should be added before other middlewares, so that it runs after all of them
+// public and bind errors are exposed to the client, private errors are only logged
+// status code set by AbortWithError/AbortWithStatus is kept, otherwise 400 when there is any bind error, or 500
+func ErrorHandler() HandlerFunc {
+	return func(c *Context) {
+		c.Next()
+		if len(c.Errors) == 0 {
+			return
+		}
+		if private := c.Errors.ByType(ErrorTypePrivate); len(private) > 0 {
+			log.Printf("[%s] %s\n%s", c.Method, c.Path, private.String())
+		}
+		// response has been sent by the handler
+		if c.Writer.Written() {
+			return
+		}
+		code := c.Writer.Status()
+		if code < http.StatusBadRequest {
+			code = http.StatusInternalServerError
+			if len(c.Errors.ByType(ErrorTypeBind)) > 0 {
+				code = http.StatusBadRequest
+			}
+		}
+		errs := make([]H, 0, len(c.Errors))
+		for _, msg := range c.Errors.ByType(ErrorTypePublic | ErrorTypeBind) {
+			errs = append(errs, msg.JSON())
+		}
+		c.JSON(code, H{"message": http.StatusText(code), "errors": errs})
+	}
+}
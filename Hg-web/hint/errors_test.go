@@ -0,0 +1,145 @@
+package hint
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAbort(t *testing.T) {
+	e := New()
+	calls := make([]string, 0)
+	e.Use(func(c *Context) {
+		calls = append(calls, "before")
+		c.Next()
+		calls = append(calls, "after")
+	})
+	e.GET("/abort", func(c *Context) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		calls = append(calls, "abort")
+	}, func(c *Context) {
+		calls = append(calls, "handler")
+	})
+	e.GET("/json", func(c *Context) {
+		c.AbortWithStatusJSON(http.StatusForbidden, H{"message": "forbidden"})
+		if !c.IsAborted() {
+			t.Error("context should be aborted")
+		}
+	}, func(c *Context) {
+		calls = append(calls, "handler")
+	})
+
+	if w := performRequest(e, http.MethodGet, "/abort"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expect 401, got %d", w.Code)
+	}
+	if strings.Join(calls, ",") != "before,abort,after" {
+		t.Fatalf("unexpected calls %v", calls)
+	}
+	w := performRequest(e, http.MethodGet, "/json")
+	if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "forbidden") {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+	}
+	if strings.Contains(strings.Join(calls, ","), "handler") {
+		t.Fatal("handler after Abort should not be called")
+	}
+}
+
+func TestContextErrors(t *testing.T) {
+	c := &Context{}
+	c.Error(errors.New("private"))
+	c.Error(errors.New("public")).SetType(ErrorTypePublic).SetMeta("meta")
+	wrapped := &Error{Err: errors.New("bind"), Type: ErrorTypeBind}
+	if c.Error(wrapped) != wrapped {
+		t.Fatal("*Error should be attached as it is")
+	}
+
+	if len(c.Errors) != 3 || c.Errors.Last() != wrapped {
+		t.Fatalf("unexpected errors %v", c.Errors)
+	}
+	if errs := c.Errors.ByType(ErrorTypePublic).Errors(); len(errs) != 1 || errs[0] != "public" {
+		t.Fatalf("unexpected public errors %v", errs)
+	}
+	if len(c.Errors.ByType(ErrorTypeAny)) != 3 {
+		t.Fatal("ErrorTypeAny should match all errors")
+	}
+	if !errors.Is(c.Errors[1], c.Errors[1].Err) {
+		t.Fatal("Error should unwrap to the origin error")
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	e := New()
+	e.Use(ErrorHandler())
+	e.GET("/private", func(c *Context) {
+		c.Error(errors.New("database is down"))
+	})
+	e.GET("/public", func(c *Context) {
+		c.Error(errors.New("quota exceeded")).SetType(ErrorTypePublic)
+		c.Error(errors.New("database is down"))
+	})
+	e.GET("/bind", func(c *Context) {
+		var obj struct {
+			Name string `form:"name" binding:"required"`
+		}
+		if err := c.ShouldBindQuery(&obj); err != nil {
+			c.Error(err).SetType(ErrorTypeBind)
+		}
+	})
+	e.GET("/written", func(c *Context) {
+		c.Error(errors.New("ignored"))
+		c.String(http.StatusOK, "ok")
+	})
+
+	tests := []struct {
+		path   string
+		code   int
+		errors []string
+	}{
+		{"/private", http.StatusInternalServerError, []string{}},
+		{"/public", http.StatusInternalServerError, []string{"quota exceeded"}},
+		{"/bind", http.StatusBadRequest, []string{"field 'Name' failed on the 'required' rule"}},
+	}
+	for _, tt := range tests {
+		w := performRequest(e, http.MethodGet, tt.path)
+		if w.Code != tt.code {
+			t.Errorf("%s: expect %d, got %d", tt.path, tt.code, w.Code)
+		}
+		var body struct {
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s: %v", tt.path, err)
+		}
+		if len(body.Errors) != len(tt.errors) {
+			t.Fatalf("%s: expect errors %v, got %s", tt.path, tt.errors, w.Body.String())
+		}
+		for i, msg := range tt.errors {
+			if body.Errors[i].Message != msg {
+				t.Errorf("%s: expect %q, got %q", tt.path, msg, body.Errors[i].Message)
+			}
+		}
+	}
+
+	if w := performRequest(e, http.MethodGet, "/written"); w.Code != http.StatusOK || w.Body.String() != "ok" {
+		t.Fatalf("response written by handler should be kept, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestErrorHandlerWithAbortWithError(t *testing.T) {
+	e := New()
+	e.Use(ErrorHandler())
+	e.GET("/private", func(c *Context) {
+		_ = c.AbortWithError(http.StatusUnauthorized, errors.New("token expired")).SetType(ErrorTypePublic)
+	})
+	w := performRequest(e, http.MethodGet, "/private")
+	if w.Code != http.StatusUnauthorized || w.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	if body := w.Body.String(); !strings.Contains(body, "token expired") {
+		t.Fatalf("unexpected body %q", body)
+	}
+}
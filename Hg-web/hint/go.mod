@@ -1,3 +1,8 @@
 module hint
 
 go 1.20
+
+require (
+	google.golang.org/protobuf v1.31.0
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -1,10 +1,15 @@
 package hint
 
 import (
+	"context"
 	"html/template"
+	"log"
+	"net"
 	"net/http"
 	"path"
 	"strings"
+	"sync"
+	"time"
 )
 
 // HandlerFunc for users define methods and actions of request path
@@ -25,6 +30,7 @@ type RouterGroup struct {
 	parent      *RouterGroup // 支持分组嵌套
 	middlewares []HandlerFunc
 	engine      *Engine // 所有分组共享一个Engine，保存一个指针方便通过Engine访问其他接口
+	routed      bool    // 本分组或子分组已经注册过路由，之后 Use 的中间件不会作用于这些路由
 }
 
 // Engine implements interface named ServeHTTP
@@ -34,6 +40,38 @@ type Engine struct {
 	groups        []*RouterGroup     // 保存所有分组，所有路由操作通过分组实现
 	htmlTemplates *template.Template // 模板加载进内存
 	funcMap       template.FuncMap   // 所有的自定义模板渲染函数
+	pool          sync.Pool          // 复用 Context，避免每个请求都分配内存
+
+	// timeouts of http.Server, 0 means no timeout, see server.go
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// ShutdownTimeout limits the connection draining of RunContext, 0 means waiting until all connections are idle
+	ShutdownTimeout time.Duration
+
+	// RemoteIPHeaders are the headers used by Context.ClientIP when the peer is a trusted proxy
+	// X-Forwarded-For, X-Real-IP and Forwarded by default
+	RemoteIPHeaders []string
+	trustedCIDRs    []*net.IPNet // see SetTrustedProxies
+
+	// SecureJSONPrefix is prepended to the arrays rendered by Context.SecureJSON, "while(1);" by default
+	SecureJSONPrefix string
+
+	// MaxMultipartMemory is the max memory used to parse multipart forms, the rest of the files are stored on disk
+	// 32MB by default, see Context.MultipartForm
+	MaxMultipartMemory int64
+
+	// codecs of Context.SetSignedCookie and Context.SetEncryptedCookie, see SetCookieKeys
+	signedCookieCodecs    []*CookieCodec
+	encryptedCookieCodecs []*CookieCodec
+
+	srvMu      sync.Mutex
+	servers    map[*http.Server]struct{}         // running servers, closed by Shutdown
+	started    bool                              // OnStart hooks run only once
+	closed     bool                              // Shutdown has been called
+	onStart    []func() error                    // lifecycle hooks, see OnStart
+	onShutdown []func(ctx context.Context) error // lifecycle hooks, see OnShutdown
 }
 
 // SetFuncMap method for users to use
@@ -48,12 +86,25 @@ func (e *Engine) LoadHTMLGlob(pattern string) {
 
 // New is the constructor of Engine for users
 func New() *Engine {
-	engine := &Engine{router: newRouter()}
+	engine := &Engine{
+		router:             newRouter(),
+		RemoteIPHeaders:    append([]string(nil), defaultRemoteIPHeaders...),
+		SecureJSONPrefix:   defaultSecureJSONPrefix,
+		MaxMultipartMemory: defaultMemory,
+	}
 	engine.RouterGroup = &RouterGroup{engine: engine}
 	engine.groups = []*RouterGroup{engine.RouterGroup}
+	engine.pool.New = func() interface{} {
+		return engine.allocateContext()
+	}
 	return engine
 }
 
+// allocateContext is called by pool when there is no free Context
+func (e *Engine) allocateContext() *Context {
+	return &Context{e: e, Params: make(Params, 0, e.router.maxParams)}
+}
+
 // Default use Logger() & Recovery middlewares
 func Default() *Engine {
 	engine := New()
@@ -77,43 +128,133 @@ func (group *RouterGroup) Group(prefix string) *RouterGroup {
 // inside func for users to add router
 // m -> http method(get/post)
 // p -> path
-// h -> handler func
-func (group *RouterGroup) addRouter(m string, p string, h HandlerFunc) {
+// handlers -> handler funcs, the middlewares of the group and its parents are prepended
+// the full handler chain is computed only once here, middlewares added by Use later don't apply
+func (group *RouterGroup) addRouter(m string, p string, handlers []HandlerFunc) {
 	pattern := group.prefix + p
-	group.engine.router.addRouter(m, pattern, h)
+	group.engine.router.addRouter(m, pattern, group.combineHandlers(handlers))
+	for g := group; g != nil; g = g.parent {
+		g.routed = true
+	}
 }
 
-// Run is a method for users to run the server on appoint port
-func (e *Engine) Run(port string) error {
-	return http.ListenAndServe(port, e)
+// combineHandlers returns middlewares of the root group ... parent group, this group, and then handlers
+func (group *RouterGroup) combineHandlers(handlers []HandlerFunc) []HandlerFunc {
+	size := len(handlers)
+	for g := group; g != nil; g = g.parent {
+		size += len(g.middlewares)
+	}
+	chain := make([]HandlerFunc, size)
+	end := size - len(handlers)
+	copy(chain[end:], handlers)
+	for g := group; g != nil; g = g.parent {
+		end -= len(g.middlewares)
+		copy(chain[end:], g.middlewares)
+	}
+	return chain
+}
+
+// hasPathPrefix is a segment-aware strings.HasPrefix
+// e.g. /v1/hello has prefix /v1 but /v10/hello doesn't
+func hasPathPrefix(path string, prefix string) bool {
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+	return len(path) == len(prefix) || prefix == "" || prefix[len(prefix)-1] == '/' || path[len(prefix)] == '/'
+}
+
+// matchGroup returns the group with the longest prefix of path
+// used to apply group middlewares when no router matched, e.g. 404 Not Found
+func (e *Engine) matchGroup(path string) *RouterGroup {
+	matched := e.RouterGroup
+	for _, g := range e.groups {
+		if len(g.prefix) > len(matched.prefix) && hasPathPrefix(path, g.prefix) {
+			matched = g
+		}
+	}
+	return matched
+}
+
+// anyMethods are the methods registered by Any
+var anyMethods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch,
+	http.MethodHead, http.MethodOptions, http.MethodDelete, http.MethodConnect,
+	http.MethodTrace,
+}
+
+// Handle is a method for users to add router with any http method
+// e.g. group.Handle("PROPFIND", "/dav/*filepath", h)
+func (group *RouterGroup) Handle(m string, p string, handlers ...HandlerFunc) {
+	if m == "" || strings.ToUpper(m) != m {
+		panic("http method " + m + " is not valid")
+	}
+	group.addRouter(m, p, handlers)
 }
 
 // GET is a method for users to add "get" router
-func (group *RouterGroup) GET(p string, h HandlerFunc) {
-	group.addRouter("GET", p, h)
+func (group *RouterGroup) GET(p string, handlers ...HandlerFunc) {
+	group.addRouter(http.MethodGet, p, handlers)
 }
 
 // POST is a method for users to add "post" router
-func (group *RouterGroup) POST(p string, h HandlerFunc) {
-	group.addRouter("POST", p, h)
+func (group *RouterGroup) POST(p string, handlers ...HandlerFunc) {
+	group.addRouter(http.MethodPost, p, handlers)
+}
+
+// PUT is a method for users to add "put" router
+func (group *RouterGroup) PUT(p string, handlers ...HandlerFunc) {
+	group.addRouter(http.MethodPut, p, handlers)
 }
 
+// PATCH is a method for users to add "patch" router
+func (group *RouterGroup) PATCH(p string, handlers ...HandlerFunc) {
+	group.addRouter(http.MethodPatch, p, handlers)
+}
+
+// DELETE is a method for users to add "delete" router
+func (group *RouterGroup) DELETE(p string, handlers ...HandlerFunc) {
+	group.addRouter(http.MethodDelete, p, handlers)
+}
+
+// HEAD is a method for users to add "head" router
+func (group *RouterGroup) HEAD(p string, handlers ...HandlerFunc) {
+	group.addRouter(http.MethodHead, p, handlers)
+}
+
+// OPTIONS is a method for users to add "options" router
+// OPTIONS requests are answered automatically when no explicit router is registered
+func (group *RouterGroup) OPTIONS(p string, handlers ...HandlerFunc) {
+	group.addRouter(http.MethodOptions, p, handlers)
+}
+
+// Any is a method for users to add router with all the common http methods
+func (group *RouterGroup) Any(p string, handlers ...HandlerFunc) {
+	for _, m := range anyMethods {
+		group.addRouter(m, p, handlers)
+	}
+}
+
+// Use adds middlewares to the group
+// the handler chain is computed when the router is added, so call Use before adding routers:
+// the middlewares added later only apply to the routers added after them and to the 404/405 responses,
+// a warning is logged when the group or its children already have routers
 func (group *RouterGroup) Use(middlewares ...HandlerFunc) {
+	if group.routed {
+		log.Printf("[WARNING] Use is called after routers of group %q are added, the middlewares don't apply to them", group.prefix)
+	}
 	group.middlewares = append(group.middlewares, middlewares...)
 }
 
 // impl interface named ServeHTTP
+// Context is taken from pool and put back when the request is finished
 func (e *Engine) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	middlewares := make([]HandlerFunc, 0)
-	for _, g := range e.groups {
-		if strings.HasPrefix(req.URL.Path, g.prefix) {
-			middlewares = append(middlewares, g.middlewares...)
-		}
-	}
-	c := newContext(w, req)
-	c.handlers = middlewares
-	c.e = e
+	c := e.pool.Get().(*Context)
+	c.reset(w, req)
 	e.router.handle(c)
+	// send the header when nothing is written, e.g. c.AbortWithStatus(401)
+	c.Writer.WriteHeaderNow()
+	c.removeMultipartForm()
+	e.pool.Put(c)
 }
 
 // create static handler
@@ -0,0 +1,164 @@
+package hint
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func performRequest(e *Engine, method, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	return w
+}
+
+func TestHTTPMethods(t *testing.T) {
+	e := New()
+	handler := func(c *Context) {
+		c.String(http.StatusOK, c.Method)
+	}
+	e.GET("/res", handler)
+	e.POST("/res", handler)
+	e.PUT("/res", handler)
+	e.PATCH("/res", handler)
+	e.DELETE("/res", handler)
+	e.HEAD("/res", handler)
+	e.Handle("PROPFIND", "/res", handler)
+	e.Any("/any", handler)
+
+	for _, m := range []string{"GET", "POST", "PUT", "PATCH", "DELETE", "PROPFIND"} {
+		w := performRequest(e, m, "/res")
+		if w.Code != http.StatusOK || w.Body.String() != m {
+			t.Fatalf("%s /res: got %d %q", m, w.Code, w.Body.String())
+		}
+	}
+	for _, m := range anyMethods {
+		if w := performRequest(e, m, "/any"); w.Code != http.StatusOK {
+			t.Fatalf("%s /any: got %d", m, w.Code)
+		}
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	e := New()
+	e.GET("/user/:id", func(c *Context) {})
+	e.DELETE("/user/:id", func(c *Context) {})
+
+	w := performRequest(e, http.MethodPost, "/user/1")
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expect 405, got %d", w.Code)
+	}
+	if allow := w.Header().Get("Allow"); allow != "DELETE, GET, OPTIONS" {
+		t.Fatalf("unexpected Allow header %q", allow)
+	}
+
+	if w = performRequest(e, http.MethodPost, "/users"); w.Code != http.StatusNotFound {
+		t.Fatalf("expect 404, got %d", w.Code)
+	}
+}
+
+func TestAutomaticOptions(t *testing.T) {
+	e := New()
+	e.GET("/user/:id", func(c *Context) {})
+	e.POST("/user/:id", func(c *Context) {})
+
+	w := performRequest(e, http.MethodOptions, "/user/1")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expect 204, got %d", w.Code)
+	}
+	if allow := w.Header().Get("Allow"); allow != "GET, OPTIONS, POST" {
+		t.Fatalf("unexpected Allow header %q", allow)
+	}
+
+	// explicit OPTIONS router takes precedence
+	e.OPTIONS("/user/:id", func(c *Context) {
+		c.String(http.StatusOK, "options")
+	})
+	if w = performRequest(e, http.MethodOptions, "/user/1"); w.Body.String() != "options" {
+		t.Fatalf("explicit OPTIONS router is not used, got %q", w.Body.String())
+	}
+}
+
+func TestGroupMiddlewares(t *testing.T) {
+	e := New()
+	trace := func(name string) HandlerFunc {
+		return func(c *Context) {
+			c.Writer.Header().Add("X-Trace", name)
+			c.Next()
+		}
+	}
+	e.Use(trace("root"))
+	v1 := e.Group("/v1")
+	v1.Use(trace("v1"))
+	admin := v1.Group("/admin")
+	admin.Use(trace("admin"))
+	v10 := e.Group("/v10")
+
+	ok := func(c *Context) { c.String(http.StatusOK, "ok") }
+	admin.GET("/users", trace("route"), ok)
+	v10.GET("/users", ok)
+
+	tests := []struct {
+		path  string
+		code  int
+		trace []string
+	}{
+		{"/v1/admin/users", http.StatusOK, []string{"root", "v1", "admin", "route"}},
+		// /v1 must not apply to /v10
+		{"/v10/users", http.StatusOK, []string{"root"}},
+		// group middlewares are applied to 404 as well
+		{"/v1/admin/unknown", http.StatusNotFound, []string{"root", "v1", "admin"}},
+		{"/v10/unknown", http.StatusNotFound, []string{"root"}},
+	}
+	for _, tt := range tests {
+		w := performRequest(e, http.MethodGet, tt.path)
+		if w.Code != tt.code {
+			t.Errorf("%s: expect %d, got %d", tt.path, tt.code, w.Code)
+		}
+		if got := w.Header().Values("X-Trace"); !reflect.DeepEqual(got, tt.trace) {
+			t.Errorf("%s: expect middlewares %v, got %v", tt.path, tt.trace, got)
+		}
+	}
+}
+
+func TestUseAfterRouters(t *testing.T) {
+	e := New()
+	v1 := e.Group("/v1")
+	ok := func(c *Context) { c.String(http.StatusOK, "ok") }
+	v1.GET("/before", ok)
+	e.Use(func(c *Context) {
+		c.Writer.Header().Set("X-Late", "1")
+		c.Next()
+	})
+	v1.GET("/after", ok)
+	if !e.routed || !v1.routed {
+		t.Fatal("groups with routers should be marked as routed")
+	}
+
+	// the chain of /before was computed before Use
+	for path, want := range map[string]string{"/v1/before": "", "/v1/after": "1", "/v1/unknown": "1"} {
+		if got := performRequest(e, http.MethodGet, path).Header().Get("X-Late"); got != want {
+			t.Errorf("%s: expect X-Late %q, got %q", path, want, got)
+		}
+	}
+}
+
+func TestContextReset(t *testing.T) {
+	e := New()
+	e.GET("/user/:id", func(c *Context) {
+		c.String(http.StatusOK, "%s %d", c.Param("id"), len(c.Params))
+	})
+	e.GET("/users", func(c *Context) {
+		c.String(http.StatusOK, "%s %d", c.FullPath(), len(c.Params))
+	})
+	for i := 0; i < 3; i++ {
+		if w := performRequest(e, http.MethodGet, "/user/42"); w.Body.String() != "42 1" {
+			t.Fatalf("unexpected body %q", w.Body.String())
+		}
+		if w := performRequest(e, http.MethodGet, "/users"); w.Body.String() != "/users 0" {
+			t.Fatalf("params of the previous request leaked, got %q", w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package hint
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// JWT(RFC 7519) bearer token 鉴权，只依赖标准库，支持 HS256/RS256/ES256 三种签名算法。
+// token 的 alg 必须与密钥的类型一致([]byte -> HS256，*rsa.PublicKey -> RS256，*ecdsa.PublicKey -> ES256)，
+// 以防止 alg=none 或者用 RSA 公钥作为 HMAC 密钥的算法混淆攻击。
+// 校验通过后，claims 以 JWTClaimsKey 保存在 Context 中，e.g.
+//
+//	api.Use(hint.JWT(hint.JWTConfig{Key: []byte(secret), Issuer: "hg", Audience: "api"}))
+//	api.GET("/me", func(c *hint.Context) {
+//		claims := c.MustGet(hint.JWTClaimsKey).(hint.JWTClaims)
+//		c.String(http.StatusOK, claims.Subject())
+//	})
+
+// JWTClaimsKey is the key of the verified JWTClaims in Context.Keys
+const JWTClaimsKey = "jwt_claims"
+
+var (
+	ErrJWTMalformed    = errors.New("jwt: malformed token")
+	ErrJWTAlgorithm    = errors.New("jwt: unexpected signing algorithm")
+	ErrJWTSignature    = errors.New("jwt: invalid signature")
+	ErrJWTExpired      = errors.New("jwt: token is expired")
+	ErrJWTNotValidYet  = errors.New("jwt: token is not valid yet")
+	ErrJWTIssuer       = errors.New("jwt: invalid issuer")
+	ErrJWTAudience     = errors.New("jwt: invalid audience")
+	ErrJWTTokenMissing = errors.New("jwt: missing bearer token")
+)
+
+// JWTClaims is the decoded payload of token, numbers are decoded as json.Number
+type JWTClaims map[string]interface{}
+
+// Subject returns the "sub" claim
+func (claims JWTClaims) Subject() string {
+	s, _ := claims["sub"].(string)
+	return s
+}
+
+// Issuer returns the "iss" claim
+func (claims JWTClaims) Issuer() string {
+	s, _ := claims["iss"].(string)
+	return s
+}
+
+// Audience returns the "aud" claim, which is either a string or an array of strings
+func (claims JWTClaims) Audience() []string {
+	switch aud := claims["aud"].(type) {
+	case string:
+		return []string{aud}
+	case []interface{}:
+		auds := make([]string, 0, len(aud))
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				auds = append(auds, s)
+			}
+		}
+		return auds
+	}
+	return nil
+}
+
+// time returns the NumericDate claim of key, ok is false when it's absent
+func (claims JWTClaims) time(key string) (t time.Time, ok bool, err error) {
+	v, found := claims[key]
+	if !found {
+		return time.Time{}, false, nil
+	}
+	n, isNumber := v.(json.Number)
+	if !isNumber {
+		return time.Time{}, false, ErrJWTMalformed
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return time.Time{}, false, ErrJWTMalformed
+	}
+	sec, frac := int64(f), f-float64(int64(f))
+	return time.Unix(sec, int64(frac*float64(time.Second))), true, nil
+}
+
+// JWTConfig defines the config for JWT middleware
+type JWTConfig struct {
+	// Key verifies the signature, []byte for HS256, *rsa.PublicKey for RS256, *ecdsa.PublicKey(P-256) for ES256
+	Key interface{}
+	// KeyFunc returns the key by the "kid" header, e.g. for key rotation, it takes precedence over Key
+	KeyFunc func(kid string) (interface{}, error)
+	// Issuer and Audience are checked when they are not empty
+	Issuer   string
+	Audience string
+	// Leeway is the allowed clock skew when checking exp and nbf
+	Leeway time.Duration
+	// Realm is used in the WWW-Authenticate header
+	Realm string
+	// TokenLookup is a function to extract the token from the request, the Authorization Bearer header by default
+	TokenLookup func(c *Context) string
+}
+
+// JWT returns a middleware which verifies the bearer token, the claims are set with JWTClaimsKey
+// failed requests are aborted with 401 and the WWW-Authenticate header
+func JWT(conf JWTConfig) HandlerFunc {
+	if conf.Key == nil && conf.KeyFunc == nil {
+		panic("JWT: Key or KeyFunc is required")
+	}
+	keyFunc := conf.KeyFunc
+	if keyFunc == nil {
+		keyFunc = func(string) (interface{}, error) { return conf.Key, nil }
+	}
+	lookup := conf.TokenLookup
+	if lookup == nil {
+		lookup = bearerToken
+	}
+	realm := conf.Realm
+	if realm == "" {
+		realm = "hint"
+	}
+
+	return func(c *Context) {
+		token := lookup(c)
+		var claims JWTClaims
+		err := ErrJWTTokenMissing
+		if token != "" {
+			claims, err = ParseJWT(token, keyFunc)
+		}
+		if err == nil {
+			err = claims.validate(conf, time.Now())
+		}
+		if err != nil {
+			c.Error(err).SetMeta(H{"client_ip": c.ClientIP()})
+			challenge := fmt.Sprintf("Bearer realm=%q", realm)
+			if token != "" {
+				challenge += fmt.Sprintf(`, error="invalid_token", error_description=%q`, err.Error())
+			}
+			c.Writer.Header().Set("WWW-Authenticate", challenge)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Set(JWTClaimsKey, claims)
+		c.Next()
+	}
+}
+
+// bearerToken returns the token of "Authorization: Bearer <token>"
+func bearerToken(c *Context) string {
+	auth := c.Req.Header.Get("Authorization")
+	if len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+		return strings.TrimSpace(auth[7:])
+	}
+	return ""
+}
+
+// validate checks exp, nbf, iss and aud
+func (claims JWTClaims) validate(conf JWTConfig, now time.Time) error {
+	exp, ok, err := claims.time("exp")
+	if err != nil {
+		return err
+	}
+	if ok && !now.Before(exp.Add(conf.Leeway)) {
+		return ErrJWTExpired
+	}
+	nbf, ok, err := claims.time("nbf")
+	if err != nil {
+		return err
+	}
+	if ok && now.Add(conf.Leeway).Before(nbf) {
+		return ErrJWTNotValidYet
+	}
+	if conf.Issuer != "" && claims.Issuer() != conf.Issuer {
+		return ErrJWTIssuer
+	}
+	if conf.Audience != "" {
+		for _, aud := range claims.Audience() {
+			if aud == conf.Audience {
+				return nil
+			}
+		}
+		return ErrJWTAudience
+	}
+	return nil
+}
+
+// ParseJWT verifies the signature of token and returns the claims, exp/nbf/iss/aud are not checked
+func ParseJWT(token string, keyFunc func(kid string) (interface{}, error)) (JWTClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrJWTMalformed
+	}
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrJWTMalformed
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err = json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, ErrJWTMalformed
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrJWTMalformed
+	}
+	key, err := keyFunc(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if err = verifyJWTSignature(header.Alg, key, parts[0]+"."+parts[1], sig); err != nil {
+		return nil, err
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrJWTMalformed
+	}
+	var claims JWTClaims
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	if err = decoder.Decode(&claims); err != nil || claims == nil {
+		return nil, ErrJWTMalformed
+	}
+	return claims, nil
+}
+
+// verifyJWTSignature verifies sig of the signing input, alg must match the type of key
+func verifyJWTSignature(alg string, key interface{}, input string, sig []byte) error {
+	hash := sha256.Sum256([]byte(input))
+	switch key := key.(type) {
+	case []byte:
+		if alg != "HS256" {
+			return ErrJWTAlgorithm
+		}
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(input))
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return ErrJWTSignature
+		}
+	case *rsa.PublicKey:
+		if alg != "RS256" {
+			return ErrJWTAlgorithm
+		}
+		if rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], sig) != nil {
+			return ErrJWTSignature
+		}
+	case *ecdsa.PublicKey:
+		if alg != "ES256" || key.Curve != elliptic.P256() {
+			return ErrJWTAlgorithm
+		}
+		// the signature is r || s, 32 bytes each
+		if len(sig) != 64 {
+			return ErrJWTSignature
+		}
+		r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(key, hash[:], r, s) {
+			return ErrJWTSignature
+		}
+	default:
+		return fmt.Errorf("jwt: unsupported key type %T", key)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package hint
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// signJWT signs the claims with alg, key is []byte, *rsa.PrivateKey or *ecdsa.PrivateKey
+func signJWT(t *testing.T, alg string, key interface{}, claims H) string {
+	t.Helper()
+	header, _ := json.Marshal(H{"alg": alg, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	hash := sha256.Sum256([]byte(input))
+	var sig []byte
+	switch key := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(input))
+		sig = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		var err error
+		if sig, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:]); err != nil {
+			t.Fatal(err)
+		}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, hash[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestParseJWT(t *testing.T) {
+	secret := []byte("secret")
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	claims := H{"sub": "hg"}
+
+	tests := []struct {
+		name  string
+		token string
+		key   interface{}
+		err   error
+	}{
+		{"HS256", signJWT(t, "HS256", secret, claims), secret, nil},
+		{"RS256", signJWT(t, "RS256", rsaKey, claims), &rsaKey.PublicKey, nil},
+		{"ES256", signJWT(t, "ES256", ecKey, claims), &ecKey.PublicKey, nil},
+		{"wrong secret", signJWT(t, "HS256", []byte("other"), claims), secret, ErrJWTSignature},
+		{"alg confusion", signJWT(t, "HS256", secret, claims), &rsaKey.PublicKey, ErrJWTAlgorithm},
+		{"alg none", signJWT(t, "none", secret, claims), secret, ErrJWTAlgorithm},
+		{"malformed", "a.b", secret, ErrJWTMalformed},
+	}
+	for _, tt := range tests {
+		got, err := ParseJWT(tt.token, func(string) (interface{}, error) { return tt.key, nil })
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: expect error %v, got %v", tt.name, tt.err, err)
+		}
+		if err == nil && got.Subject() != "hg" {
+			t.Errorf("%s: unexpected claims %v", tt.name, got)
+		}
+	}
+}
+
+func TestJWTMiddleware(t *testing.T) {
+	secret := []byte("secret")
+	e := New()
+	e.Use(JWT(JWTConfig{Key: secret, Issuer: "hg", Audience: "api", Leeway: time.Second}))
+	e.GET("/me", func(c *Context) {
+		c.String(http.StatusOK, c.MustGet(JWTClaimsKey).(JWTClaims).Subject())
+	})
+
+	now := time.Now().Unix()
+	tests := []struct {
+		name   string
+		claims H
+		code   int
+	}{
+		{"valid", H{"sub": "hg", "iss": "hg", "aud": []string{"web", "api"}, "exp": now + 60, "nbf": now}, http.StatusOK},
+		{"expired", H{"sub": "hg", "iss": "hg", "aud": "api", "exp": now - 60}, http.StatusUnauthorized},
+		{"not valid yet", H{"sub": "hg", "iss": "hg", "aud": "api", "nbf": now + 60}, http.StatusUnauthorized},
+		{"wrong issuer", H{"sub": "hg", "iss": "other", "aud": "api"}, http.StatusUnauthorized},
+		{"wrong audience", H{"sub": "hg", "iss": "hg", "aud": "web"}, http.StatusUnauthorized},
+		{"missing token", nil, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		if tt.claims != nil {
+			req.Header.Set("Authorization", "Bearer "+signJWT(t, "HS256", secret, tt.claims))
+		}
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Errorf("%s: expect %d, got %d", tt.name, tt.code, w.Code)
+		}
+		if tt.code == http.StatusOK && w.Body.String() != "hg" {
+			t.Errorf("%s: unexpected body %q", tt.name, w.Body.String())
+		}
+		if tt.code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("%s: missing WWW-Authenticate", tt.name)
+		}
+	}
+}
@@ -1,17 +1,241 @@
 package hint
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
 	"log"
+	"net/http"
+	"strings"
+	"sync"
 	"time"
 )
 
+// 访问日志支持以下格式:
+// LogFormatDefault  [200] 127.0.0.1 GET /p/go/doc 13B in 1.2ms
+// LogFormatCommon   Apache Common Log Format, 127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326
+// LogFormatCombined Apache Combined Log Format, Common 之后追加 "Referer" "User-Agent"
+// LogFormatJSON     每个请求一行 JSON
+// Common/Combined 在标准字段之后追加耗时、Trace 中间件设置的请求 ID 和 trace ID 以及选定的请求/响应头，例如 1.2ms request_id="abc" req.Accept="*/*"。
+// 敏感的请求头与 query 参数在输出前替换为 redacted。
+
+// LogFormat is the format of access log
+type LogFormat int
+
+const (
+	LogFormatDefault LogFormat = iota
+	LogFormatCommon
+	LogFormatCombined
+	LogFormatJSON
+)
+
+const redacted = "[REDACTED]"
+
+// DefaultRedactHeaders are redacted when LoggerConfig.RedactHeaders is nil
+var DefaultRedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// LoggerConfig defines the config for Logger middleware
+type LoggerConfig struct {
+	// Format of the log line, LogFormatDefault by default
+	Format LogFormat
+	// Output is the writer of log lines, the output of the standard logger (log.Writer()) by default
+	Output io.Writer
+	// SkipPaths are the request paths not logged, e.g. /healthz
+	SkipPaths []string
+	// RequestHeaders and ResponseHeaders are the headers to be logged
+	RequestHeaders  []string
+	ResponseHeaders []string
+	// RedactHeaders are the headers whose values are replaced with [REDACTED], DefaultRedactHeaders by default
+	RedactHeaders []string
+	// RedactQueryParams are the query params whose values are replaced with [REDACTED], e.g. token, password
+	RedactQueryParams []string
+}
+
+// LogEntry is a single access log, it is also the structure of LogFormatJSON
+type LogEntry struct {
+	Time            time.Time         `json:"time"`
+	ClientIP        string            `json:"client_ip"`
+	Method          string            `json:"method"`
+	URI             string            `json:"uri"`
+	Route           string            `json:"route,omitempty"`
+	Proto           string            `json:"proto"`
+	Status          int               `json:"status"`
+	Size            int               `json:"size"`
+	Latency         time.Duration     `json:"latency_ns"`
+	User            string            `json:"user,omitempty"`
+	Referer         string            `json:"referer,omitempty"`
+	UserAgent       string            `json:"user_agent,omitempty"`
+	RequestHeaders  map[string]string `json:"request_headers,omitempty"`
+	ResponseHeaders map[string]string `json:"response_headers,omitempty"`
+	Errors          []string          `json:"errors,omitempty"`
+	// RequestID and TraceID are set by Trace middleware
+	RequestID string `json:"request_id,omitempty"`
+	TraceID   string `json:"trace_id,omitempty"`
+}
+
+// Logger returns a middleware which logs the requests in the default format
 func Logger() HandlerFunc {
+	return LoggerWithConfig(LoggerConfig{})
+}
+
+// LoggerWithConfig returns a Logger middleware with config
+func LoggerWithConfig(conf LoggerConfig) HandlerFunc {
+	out := conf.Output
+	if out == nil {
+		out = log.Writer()
+	}
+	skip := make(map[string]struct{}, len(conf.SkipPaths))
+	for _, p := range conf.SkipPaths {
+		skip[p] = struct{}{}
+	}
+	redactHeaders := conf.RedactHeaders
+	if redactHeaders == nil {
+		redactHeaders = DefaultRedactHeaders
+	}
+	redactHeaderSet := make(map[string]struct{}, len(redactHeaders))
+	for _, h := range redactHeaders {
+		redactHeaderSet[http.CanonicalHeaderKey(h)] = struct{}{}
+	}
+	redactQuerySet := make(map[string]struct{}, len(conf.RedactQueryParams))
+	for _, q := range conf.RedactQueryParams {
+		redactQuerySet[strings.ToLower(q)] = struct{}{}
+	}
+	// log.Logger serializes the writes, other formats are guarded by mu
+	stdLogger := log.New(out, "", log.LstdFlags)
+	var mu sync.Mutex
+
 	return func(c *Context) {
+		if _, ok := skip[c.Path]; ok {
+			c.Next()
+			return
+		}
 		// Start timer
 		t := time.Now()
 		// Process request
 		c.Next()
-		// Calculate resolution time
-		log.Printf("[%d] %s in %v", c.StatusCode, c.Req.RequestURI, time.Since(t))
+
+		entry := LogEntry{
+			Time:      t,
+			ClientIP:  c.ClientIP(),
+			Method:    c.Method,
+			URI:       redactURI(c.Req.RequestURI, redactQuerySet),
+			Route:     c.FullPath(),
+			Proto:     c.Req.Proto,
+			Status:    c.Writer.Status(),
+			Size:      c.Writer.Size(),
+			Latency:   time.Since(t),
+			Referer:   c.Req.Referer(),
+			UserAgent: c.Req.UserAgent(),
+			Errors:    c.Errors.Errors(),
+			RequestID: c.RequestID(),
+		}
+		if tc := c.TraceContext(); tc != nil {
+			entry.TraceID = tc.TraceID
+		}
+		if entry.Size < 0 {
+			entry.Size = 0
+		}
+		if user, _, ok := c.Req.BasicAuth(); ok {
+			entry.User = user
+		}
+		entry.RequestHeaders = pickHeaders(c.Req.Header, conf.RequestHeaders, redactHeaderSet)
+		entry.ResponseHeaders = pickHeaders(c.Writer.Header(), conf.ResponseHeaders, redactHeaderSet)
+
+		switch conf.Format {
+		case LogFormatCommon, LogFormatCombined, LogFormatJSON:
+			line := formatEntry(conf.Format, &entry, conf.RequestHeaders, conf.ResponseHeaders)
+			mu.Lock()
+			_, _ = io.WriteString(out, line)
+			mu.Unlock()
+		default:
+			if entry.RequestID != "" {
+				stdLogger.Printf("[%d] %s %s %s %dB in %v request_id=%s", entry.Status, entry.ClientIP, entry.Method, entry.URI, entry.Size, entry.Latency, entry.RequestID)
+			} else {
+				stdLogger.Printf("[%d] %s %s %s %dB in %v", entry.Status, entry.ClientIP, entry.Method, entry.URI, entry.Size, entry.Latency)
+			}
+		}
+	}
+}
+
+// formatEntry returns a log line ending with "\n"
+func formatEntry(format LogFormat, entry *LogEntry, reqHeaders []string, respHeaders []string) string {
+	if format == LogFormatJSON {
+		b, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Sprintf("{\"error\":%q}\n", err.Error())
+		}
+		return string(b) + "\n"
+	}
+	var sb strings.Builder
+	size := "-"
+	if entry.Size > 0 {
+		size = fmt.Sprint(entry.Size)
+	}
+	fmt.Fprintf(&sb, "%s - %s [%s] \"%s %s %s\" %d %s",
+		orDash(entry.ClientIP), orDash(entry.User), entry.Time.Format("02/Jan/2006:15:04:05 -0700"),
+		entry.Method, entry.URI, entry.Proto, entry.Status, size)
+	if format == LogFormatCombined {
+		fmt.Fprintf(&sb, " \"%s\" \"%s\"", orDash(entry.Referer), orDash(entry.UserAgent))
+	}
+	fmt.Fprintf(&sb, " %v", entry.Latency)
+	if entry.RequestID != "" {
+		fmt.Fprintf(&sb, " request_id=%q", entry.RequestID)
+	}
+	if entry.TraceID != "" {
+		fmt.Fprintf(&sb, " trace_id=%s", entry.TraceID)
+	}
+	for _, h := range reqHeaders {
+		h = http.CanonicalHeaderKey(h)
+		fmt.Fprintf(&sb, " req.%s=%q", h, entry.RequestHeaders[h])
+	}
+	for _, h := range respHeaders {
+		h = http.CanonicalHeaderKey(h)
+		fmt.Fprintf(&sb, " resp.%s=%q", h, entry.ResponseHeaders[h])
+	}
+	sb.WriteByte('\n')
+	return sb.String()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// pickHeaders returns the values of names in h, the values of redact are replaced
+func pickHeaders(h http.Header, names []string, redact map[string]struct{}) map[string]string {
+	if len(names) == 0 {
+		return nil
+	}
+	picked := make(map[string]string, len(names))
+	for _, name := range names {
+		name = http.CanonicalHeaderKey(name)
+		value := strings.Join(h.Values(name), ", ")
+		if _, ok := redact[name]; ok && value != "" {
+			value = redacted
+		}
+		picked[name] = value
+	}
+	return picked
+}
+
+// redactURI replaces the values of the query params in redact, the order of params is kept
+// e.g. /login?user=hg&token=abc -> /login?user=hg&token=[REDACTED]
+func redactURI(uri string, redact map[string]struct{}) string {
+	i := strings.IndexByte(uri, '?')
+	if len(redact) == 0 || i < 0 {
+		return uri
+	}
+	params := strings.Split(uri[i+1:], "&")
+	for j, param := range params {
+		key := param
+		if k := strings.IndexByte(param, '='); k >= 0 {
+			key = param[:k]
+		}
+		if _, ok := redact[strings.ToLower(key)]; ok {
+			params[j] = key + "=" + redacted
+		}
 	}
+	return uri[:i+1] + strings.Join(params, "&")
 }
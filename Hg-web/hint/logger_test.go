@@ -0,0 +1,66 @@
+package hint
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLoggerFormats(t *testing.T) {
+	var buf bytes.Buffer
+	newEngine := func(format LogFormat) *Engine {
+		e := New()
+		e.Use(LoggerWithConfig(LoggerConfig{
+			Format:            format,
+			Output:            &buf,
+			SkipPaths:         []string{"/healthz"},
+			RequestHeaders:    []string{"authorization", "X-Request-Id"},
+			ResponseHeaders:   []string{"Content-Type"},
+			RedactQueryParams: []string{"token"},
+		}))
+		e.GET("/user/:id", func(c *Context) {
+			c.String(http.StatusCreated, "hello")
+		})
+		e.GET("/healthz", func(c *Context) {})
+		return e
+	}
+	request := func(e *Engine, path string) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("Authorization", "Bearer secret")
+		req.Header.Set("X-Request-Id", "abc")
+		req.Header.Set("User-Agent", "curl/8.0")
+		e.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	request(newEngine(LogFormatCombined), "/user/1?name=hg&token=abc")
+	line := buf.String()
+	for _, want := range []string{
+		`10.0.0.1 - - [`,
+		`"GET /user/1?name=hg&token=[REDACTED] HTTP/1.1" 201 5 "-" "curl/8.0" `,
+		`req.Authorization="[REDACTED]" req.X-Request-Id="abc" resp.Content-Type="text/plain"`,
+	} {
+		if !strings.Contains(line, want) {
+			t.Errorf("combined log %q doesn't contain %q", line, want)
+		}
+	}
+
+	buf.Reset()
+	e := newEngine(LogFormatJSON)
+	request(e, "/healthz")
+	if buf.Len() != 0 {
+		t.Fatalf("skipped path is logged: %q", buf.String())
+	}
+	request(e, "/user/2?token=abc")
+	var entry LogEntry
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid json log %q: %v", buf.String(), err)
+	}
+	if entry.ClientIP != "10.0.0.1" || entry.Route != "/user/:id" || entry.Status != 201 || entry.Size != 5 ||
+		entry.URI != "/user/2?token=[REDACTED]" || entry.RequestHeaders["Authorization"] != redacted {
+		t.Fatalf("unexpected json log %+v", entry)
+	}
+}
@@ -0,0 +1,228 @@
+package hint
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 请求指标，以 Prometheus 文本格式(https://prometheus.io/docs/instrumenting/exposition_formats/)输出，不依赖 Prometheus 客户端库。
+// 指标按 method、route(匹配的路由规则，例如 /p/:lang/doc，而不是实际路径，避免标签数量无限增长)和 status 区分:
+// hint_http_requests_total              请求数(counter)
+// hint_http_request_duration_seconds    处理耗时(histogram)
+// hint_http_requests_in_flight          正在处理的请求数(gauge，按 method、route 区分)
+// 没有匹配到路由的请求(404/405 等) route 为 unmatched，标准方法之外的 method 为 other，客户端无法任意制造新的标签，e.g.
+//
+//	m := hint.NewMetrics(hint.MetricsConfig{})
+//	e.Use(m.Middleware())
+//	e.GET("/metrics", m.Handler())
+
+// DefaultMetricsBuckets are the default buckets of latency histogram in seconds
+var DefaultMetricsBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+const (
+	metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+	unmatchedRoute     = "unmatched"
+	otherMethod        = "other"
+)
+
+// MetricsConfig defines the config for Metrics
+type MetricsConfig struct {
+	// Namespace is the prefix of metric names, "hint" by default
+	Namespace string
+	// Buckets are the upper bounds of latency histogram in seconds, DefaultMetricsBuckets by default
+	Buckets []float64
+}
+
+// Metrics collects the request metrics, it must be created by NewMetrics
+type Metrics struct {
+	namespace string
+	buckets   []float64
+
+	mu       sync.Mutex
+	requests map[requestLabels]*requestStats
+	inFlight map[routeLabels]int64
+}
+
+type routeLabels struct {
+	method string
+	route  string
+}
+
+type requestLabels struct {
+	routeLabels
+	status int
+}
+
+type requestStats struct {
+	count   uint64
+	sum     float64
+	buckets []uint64 // counts of each bucket, not cumulative
+}
+
+// NewMetrics is the constructor of Metrics
+func NewMetrics(conf MetricsConfig) *Metrics {
+	namespace := conf.Namespace
+	if namespace == "" {
+		namespace = "hint"
+	}
+	buckets := conf.Buckets
+	if buckets == nil {
+		buckets = DefaultMetricsBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Metrics{
+		namespace: namespace,
+		buckets:   buckets,
+		requests:  make(map[requestLabels]*requestStats),
+		inFlight:  make(map[routeLabels]int64),
+	}
+}
+
+// Middleware returns a middleware which records the requests
+func (m *Metrics) Middleware() HandlerFunc {
+	return func(c *Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		labels := routeLabels{method: metricsMethod(c.Method), route: route}
+		m.mu.Lock()
+		m.inFlight[labels]++
+		m.mu.Unlock()
+
+		start := time.Now()
+		defer func() {
+			m.observe(requestLabels{routeLabels: labels, status: c.Writer.Status()}, time.Since(start).Seconds())
+		}()
+		c.Next()
+	}
+}
+
+// metricsMethod returns the method label, the methods out of the standard set are "other"
+func metricsMethod(method string) string {
+	for _, m := range anyMethods {
+		if method == m {
+			return m
+		}
+	}
+	return otherMethod
+}
+
+func (m *Metrics) observe(labels requestLabels, seconds float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight[labels.routeLabels]--
+	stats, ok := m.requests[labels]
+	if !ok {
+		stats = &requestStats{buckets: make([]uint64, len(m.buckets))}
+		m.requests[labels] = stats
+	}
+	stats.count++
+	stats.sum += seconds
+	if i := sort.SearchFloat64s(m.buckets, seconds); i < len(m.buckets) {
+		stats.buckets[i]++
+	}
+}
+
+// Handler returns a handler which writes the metrics in the Prometheus text format
+func (m *Metrics) Handler() HandlerFunc {
+	return func(c *Context) {
+		c.SetHeader("Content-Type", metricsContentType)
+		c.Status(http.StatusOK)
+		_ = m.Export(c.Writer)
+	}
+}
+
+// ServeHTTP implements http.Handler, so that the metrics can be served by another server
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", metricsContentType)
+	_ = m.Export(w)
+}
+
+// Export writes the metrics in the Prometheus text format, the series are sorted by labels
+func (m *Metrics) Export(w io.Writer) error {
+	m.mu.Lock()
+	requests := make([]requestLabels, 0, len(m.requests))
+	snapshot := make(map[requestLabels]requestStats, len(m.requests))
+	for labels, stats := range m.requests {
+		requests = append(requests, labels)
+		snapshot[labels] = requestStats{count: stats.count, sum: stats.sum, buckets: append([]uint64(nil), stats.buckets...)}
+	}
+	inFlight := make([]routeLabels, 0, len(m.inFlight))
+	gauges := make(map[routeLabels]int64, len(m.inFlight))
+	for labels, n := range m.inFlight {
+		inFlight = append(inFlight, labels)
+		gauges[labels] = n
+	}
+	m.mu.Unlock()
+
+	sort.Slice(requests, func(i, j int) bool {
+		a, b := requests[i], requests[j]
+		if a.routeLabels != b.routeLabels {
+			return a.routeLabels.less(b.routeLabels)
+		}
+		return a.status < b.status
+	})
+	sort.Slice(inFlight, func(i, j int) bool { return inFlight[i].less(inFlight[j]) })
+
+	var sb strings.Builder
+	name := m.namespace + "_http_requests_total"
+	fmt.Fprintf(&sb, "# HELP %s Total number of HTTP requests.\n# TYPE %s counter\n", name, name)
+	for _, labels := range requests {
+		fmt.Fprintf(&sb, "%s{%s} %d\n", name, labels.String(), snapshot[labels].count)
+	}
+
+	name = m.namespace + "_http_request_duration_seconds"
+	fmt.Fprintf(&sb, "# HELP %s Latency of HTTP requests in seconds.\n# TYPE %s histogram\n", name, name)
+	for _, labels := range requests {
+		stats := snapshot[labels]
+		l := labels.String()
+		var cumulative uint64
+		for i, bound := range m.buckets {
+			cumulative += stats.buckets[i]
+			fmt.Fprintf(&sb, "%s_bucket{%s,le=\"%s\"} %d\n", name, l, formatFloat(bound), cumulative)
+		}
+		fmt.Fprintf(&sb, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, l, stats.count)
+		fmt.Fprintf(&sb, "%s_sum{%s} %s\n", name, l, formatFloat(stats.sum))
+		fmt.Fprintf(&sb, "%s_count{%s} %d\n", name, l, stats.count)
+	}
+
+	name = m.namespace + "_http_requests_in_flight"
+	fmt.Fprintf(&sb, "# HELP %s Number of HTTP requests being served.\n# TYPE %s gauge\n", name, name)
+	for _, labels := range inFlight {
+		fmt.Fprintf(&sb, "%s{%s} %d\n", name, labels.String(), gauges[labels])
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (l routeLabels) less(o routeLabels) bool {
+	if l.route != o.route {
+		return l.route < o.route
+	}
+	return l.method < o.method
+}
+
+func (l routeLabels) String() string {
+	return `method="` + escapeLabelValue(l.method) + `",route="` + escapeLabelValue(l.route) + `"`
+}
+
+func (l requestLabels) String() string {
+	return l.routeLabels.String() + `,status="` + strconv.Itoa(l.status) + `"`
+}
+
+// escapeLabelValue escapes backslash, double-quote and line feed
+func escapeLabelValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,65 @@
+package hint
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics(MetricsConfig{Buckets: []float64{1, 0.1}})
+	e := New()
+	e.Use(m.Middleware())
+	e.GET("/metrics", m.Handler())
+	e.GET("/p/:lang", func(c *Context) {
+		var sb strings.Builder
+		_ = m.Export(&sb)
+		// the current request is in flight
+		if !strings.Contains(sb.String(), `hint_http_requests_in_flight{method="GET",route="/p/:lang"} 1`) {
+			t.Errorf("in-flight gauge is not recorded:\n%s", sb.String())
+		}
+		c.String(http.StatusOK, c.Param("lang"))
+	})
+
+	performRequest(e, http.MethodGet, "/p/go")
+	performRequest(e, http.MethodGet, "/p/c")
+	performRequest(e, http.MethodGet, "/unknown")
+	performRequest(e, "FOO", "/p/go")
+	performRequest(e, "BAR", "/unknown")
+
+	w := performRequest(e, http.MethodGet, "/metrics")
+	if w.Header().Get("Content-Type") != metricsContentType {
+		t.Fatalf("unexpected Content-Type %q", w.Header().Get("Content-Type"))
+	}
+	body := w.Body.String()
+	for _, want := range []string{
+		"# TYPE hint_http_requests_total counter\n",
+		`hint_http_requests_total{method="GET",route="/p/:lang",status="200"} 2` + "\n",
+		`hint_http_requests_total{method="GET",route="unmatched",status="404"} 1` + "\n",
+		// the made-up methods share one label
+		`hint_http_requests_total{method="other",route="unmatched",status="404"} 1` + "\n",
+		`hint_http_requests_total{method="other",route="unmatched",status="405"} 1` + "\n",
+		"# TYPE hint_http_request_duration_seconds histogram\n",
+		`hint_http_request_duration_seconds_bucket{method="GET",route="/p/:lang",status="200",le="0.1"} 2` + "\n",
+		`hint_http_request_duration_seconds_bucket{method="GET",route="/p/:lang",status="200",le="+Inf"} 2` + "\n",
+		`hint_http_request_duration_seconds_count{method="GET",route="/p/:lang",status="200"} 2` + "\n",
+		`hint_http_requests_in_flight{method="GET",route="/p/:lang"} 0` + "\n",
+		// the request of /metrics itself
+		`hint_http_requests_in_flight{method="GET",route="/metrics"} 1` + "\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics don't contain %q:\n%s", want, body)
+		}
+	}
+	for _, method := range []string{"FOO", "BAR"} {
+		if strings.Contains(body, `method="`+method+`"`) {
+			t.Errorf("method %s should not be a label:\n%s", method, body)
+		}
+	}
+}
+
+func TestEscapeLabelValue(t *testing.T) {
+	if got := escapeLabelValue("a\"b\\c\nd"); got != `a\"b\\c\nd` {
+		t.Fatalf("unexpected escaped value %s", got)
+	}
+}
@@ -0,0 +1,131 @@
+package hint
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 内容协商: 根据请求的 Accept 头在服务端提供的格式中选择一种，同一个 handler 可以给浏览器返回 JSON，给内部服务返回 protobuf，e.g.
+//
+//	c.Negotiate(http.StatusOK, hint.Negotiate{
+//		Offered:      []string{hint.MIMEJSON, hint.MIMEPROTOBUF},
+//		Data:         user,
+//		ProtoBufData: userPB,
+//	})
+//
+// Accept 中 q 值最高的格式优先，q 值相同时按 Offered 的顺序；没有 Accept 头时使用 Offered 的第一个，都不可接受时返回 406。
+
+// Negotiate contains all negotiations data
+type Negotiate struct {
+	// Offered are the MIME types in the order of preference, e.g. MIMEJSON, MIMEXML, MIMEYAML, MIMEPROTOBUF, MIMEHTML
+	Offered []string
+	// Data is rendered when the data of the negotiated format is nil
+	Data         interface{}
+	JSONData     interface{}
+	XMLData      interface{}
+	YAMLData     interface{}
+	ProtoBufData interface{}
+	// HTMLName is the template rendered with HTMLData (or Data) for MIMEHTML
+	HTMLName string
+	HTMLData interface{}
+}
+
+// Negotiate renders the data in the format negotiated by the Accept header
+// 406 is returned when none of the offered formats is acceptable
+func (c *Context) Negotiate(code int, config Negotiate) {
+	pick := func(data interface{}) interface{} {
+		if data != nil {
+			return data
+		}
+		return config.Data
+	}
+	switch c.NegotiateFormat(config.Offered...) {
+	case MIMEJSON:
+		c.JSON(code, pick(config.JSONData))
+	case MIMEXML, MIMEXML2:
+		c.XML(code, pick(config.XMLData))
+	case MIMEYAML:
+		c.YAML(code, pick(config.YAMLData))
+	case MIMEPROTOBUF:
+		c.ProtoBuf(code, pick(config.ProtoBufData))
+	case MIMEHTML:
+		c.HTML(code, config.HTMLName, pick(config.HTMLData))
+	default:
+		c.Fail(http.StatusNotAcceptable, "the accepted formats are not offered by the server")
+	}
+}
+
+// NegotiateFormat returns the offered MIME type preferred by the Accept header, "" when none is acceptable
+func (c *Context) NegotiateFormat(offered ...string) string {
+	if len(offered) == 0 {
+		panic("you must provide at least one offer")
+	}
+	accepts := parseAccept(c.Req.Header.Values("Accept"))
+	if len(accepts) == 0 {
+		return offered[0]
+	}
+	best, bestQ := "", 0.0
+	for _, offer := range offered {
+		if q := acceptQuality(accepts, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+type acceptRange struct {
+	typ, subtype string
+	q            float64
+}
+
+// specificity is 2 for type/subtype, 1 for type/* and 0 for */*
+func (a acceptRange) specificity() int {
+	switch {
+	case a.typ == "*":
+		return 0
+	case a.subtype == "*":
+		return 1
+	}
+	return 2
+}
+
+// parseAccept parses the media ranges, the more specific ones come first
+// e.g. "text/*;q=0.5, application/json" -> application/json(q=1), text/*(q=0.5)
+func parseAccept(values []string) []acceptRange {
+	var accepts []acceptRange
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			mediaRange, params, _ := strings.Cut(part, ";")
+			typ, subtype, ok := strings.Cut(strings.ToLower(strings.TrimSpace(mediaRange)), "/")
+			if !ok || typ == "" || subtype == "" {
+				continue
+			}
+			a := acceptRange{typ: typ, subtype: subtype, q: 1}
+			for _, param := range strings.Split(params, ";") {
+				if v, found := strings.CutPrefix(strings.TrimSpace(param), "q="); found {
+					if q, err := strconv.ParseFloat(v, 64); err == nil {
+						a.q = q
+					}
+				}
+			}
+			accepts = append(accepts, a)
+		}
+	}
+	sort.SliceStable(accepts, func(i, j int) bool {
+		return accepts[i].specificity() > accepts[j].specificity()
+	})
+	return accepts
+}
+
+// acceptQuality returns the quality of the most specific range matching mime, 0 when it's not acceptable
+func acceptQuality(accepts []acceptRange, mime string) float64 {
+	typ, subtype, _ := strings.Cut(strings.ToLower(mime), "/")
+	for _, a := range accepts {
+		if (a.typ == "*" || a.typ == typ) && (a.subtype == "*" || a.subtype == subtype) {
+			return a.q
+		}
+	}
+	return 0
+}
@@ -0,0 +1,276 @@
+package hint
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 限流中间件，支持两种算法:
+// 令牌桶(TokenBucket): 桶容量为 Burst，每 Window/Limit 补充一个令牌，允许短时间的突发流量。
+// 滑动窗口(SlidingWindow): 用上一个窗口的计数按时间比例加权估算最近 Window 内的请求数，
+// 避免固定窗口在边界处放行两倍的请求，且每个 key 只需要保存两个计数。
+// 限流的 key 可以是客户端 IP、请求头、路由或自定义函数，e.g.
+//
+//	api.Use(hint.RateLimit(hint.RateLimitConfig{
+//		Rule:    hint.RateLimitRule{Algorithm: hint.SlidingWindow, Limit: 100, Window: time.Minute},
+//		KeyFunc: hint.RateLimitByKeys(hint.RateLimitByRoute, hint.RateLimitByClientIP),
+//	}))
+//
+// 状态保存在 RateLimitStore 中，默认是进程内存，多实例部署时可以实现基于 redis 等的共享存储。
+
+// RateLimitAlgorithm is the algorithm of rate limiting
+type RateLimitAlgorithm int
+
+const (
+	TokenBucket RateLimitAlgorithm = iota
+	SlidingWindow
+)
+
+// RateLimitRule allows Limit requests per Window
+type RateLimitRule struct {
+	Algorithm RateLimitAlgorithm
+	Limit     int
+	Window    time.Duration
+	// Burst is the capacity of TokenBucket, Limit by default
+	Burst int
+}
+
+// RateLimitResult is the result of a request taken by RateLimitStore
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the duration until the quota is fully restored
+	Reset time.Duration
+	// RetryAfter is the duration until the next request is allowed, 0 when Allowed
+	RetryAfter time.Duration
+}
+
+// RateLimitStore stores the state of rate limiting by key, it must be safe for concurrent use
+type RateLimitStore interface {
+	// Take takes a request of key under the rule
+	Take(ctx context.Context, key string, rule RateLimitRule) (RateLimitResult, error)
+}
+
+// RateLimitKeyFunc returns the key to be limited, "" means the request is not limited
+type RateLimitKeyFunc func(c *Context) string
+
+// RateLimitConfig defines the config for RateLimit middleware
+type RateLimitConfig struct {
+	Rule RateLimitRule
+	// Store is an in-memory store by default
+	Store RateLimitStore
+	// KeyFunc is RateLimitByClientIP by default
+	KeyFunc RateLimitKeyFunc
+	// Prefix is prepended to the keys, so that middlewares with different rules can share a Store
+	Prefix string
+}
+
+// RateLimitByClientIP limits by Context.ClientIP
+func RateLimitByClientIP(c *Context) string {
+	return c.ClientIP()
+}
+
+// RateLimitByRoute limits by the method and the matched pattern of router, e.g. GET /p/:lang/doc
+func RateLimitByRoute(c *Context) string {
+	return c.Method + " " + c.FullPath()
+}
+
+// RateLimitByHeader limits by the value of request header, e.g. X-API-Key
+// requests without the header are not limited
+func RateLimitByHeader(name string) RateLimitKeyFunc {
+	return func(c *Context) string {
+		return c.Req.Header.Get(name)
+	}
+}
+
+// RateLimitByKeys combines key funcs, the request is not limited when any of them returns ""
+func RateLimitByKeys(funcs ...RateLimitKeyFunc) RateLimitKeyFunc {
+	return func(c *Context) string {
+		key := ""
+		for i, f := range funcs {
+			k := f(c)
+			if k == "" {
+				return ""
+			}
+			if i > 0 {
+				key += "|"
+			}
+			key += k
+		}
+		return key
+	}
+}
+
+// RateLimit returns a middleware which aborts the requests exceeding the rule with 429
+// RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy headers are set on every limited request,
+// and Retry-After is set on 429. Requests are allowed when the Store fails, the error is attached to Context
+func RateLimit(conf RateLimitConfig) HandlerFunc {
+	rule := conf.Rule
+	if rule.Limit <= 0 || rule.Window <= 0 {
+		panic("RateLimit: Limit and Window must be positive")
+	}
+	if rule.Burst <= 0 {
+		rule.Burst = rule.Limit
+	}
+	store := conf.Store
+	if store == nil {
+		store = NewMemoryRateLimitStore()
+	}
+	keyFunc := conf.KeyFunc
+	if keyFunc == nil {
+		keyFunc = RateLimitByClientIP
+	}
+	policy := fmt.Sprintf("%d;w=%d", rule.Limit, int64(math.Ceil(rule.Window.Seconds())))
+
+	return func(c *Context) {
+		key := keyFunc(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+		result, err := store.Take(c, conf.Prefix+key, rule)
+		if err != nil {
+			_ = c.Error(fmt.Errorf("rate limit: %w", err))
+			c.Next()
+			return
+		}
+		header := c.Writer.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		header.Set("RateLimit-Reset", strconv.FormatInt(ceilSeconds(result.Reset), 10))
+		header.Set("RateLimit-Policy", policy)
+		if !result.Allowed {
+			header.Set("Retry-After", strconv.FormatInt(ceilSeconds(result.RetryAfter), 10))
+			c.Fail(http.StatusTooManyRequests, http.StatusText(http.StatusTooManyRequests))
+			return
+		}
+		c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int64 {
+	return int64(math.Ceil(d.Seconds()))
+}
+
+// MemoryRateLimitStore is a RateLimitStore in process memory, expired keys are swept lazily
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	entries   map[string]*rateLimitEntry
+	nextSweep time.Time
+	now       func() time.Time
+}
+
+type rateLimitEntry struct {
+	// token bucket
+	tokens float64
+	last   time.Time
+	// sliding window
+	windowStart time.Time
+	prev, curr  int
+
+	expire time.Time
+}
+
+var _ RateLimitStore = (*MemoryRateLimitStore)(nil)
+
+// NewMemoryRateLimitStore is the constructor of MemoryRateLimitStore
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{entries: make(map[string]*rateLimitEntry), now: time.Now}
+}
+
+// Take implements RateLimitStore
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, rule RateLimitRule) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if now.After(s.nextSweep) {
+		for k, entry := range s.entries {
+			if now.After(entry.expire) {
+				delete(s.entries, k)
+			}
+		}
+		s.nextSweep = now.Add(time.Minute)
+	}
+	entry, ok := s.entries[key]
+	if !ok {
+		entry = &rateLimitEntry{tokens: float64(rule.Burst), last: now, windowStart: now}
+		s.entries[key] = entry
+	}
+	if rule.Algorithm == SlidingWindow {
+		return entry.slidingWindow(rule, now), nil
+	}
+	return entry.tokenBucket(rule, now), nil
+}
+
+func (entry *rateLimitEntry) tokenBucket(rule RateLimitRule, now time.Time) RateLimitResult {
+	// the duration to refill a token
+	interval := rule.Window / time.Duration(rule.Limit)
+	capacity := float64(rule.Burst)
+	entry.tokens = math.Min(capacity, entry.tokens+float64(now.Sub(entry.last))/float64(interval))
+	entry.last = now
+
+	result := RateLimitResult{Limit: rule.Burst}
+	if entry.tokens >= 1 {
+		entry.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - entry.tokens) * float64(interval))
+	}
+	result.Remaining = int(entry.tokens)
+	result.Reset = time.Duration((capacity - entry.tokens) * float64(interval))
+	entry.expire = now.Add(result.Reset)
+	return result
+}
+
+func (entry *rateLimitEntry) slidingWindow(rule RateLimitRule, now time.Time) RateLimitResult {
+	window := rule.Window
+	if elapsed := now.Sub(entry.windowStart); elapsed >= window {
+		// roll the window, the previous count is dropped when more than one window passed
+		if elapsed < 2*window {
+			entry.prev = entry.curr
+		} else {
+			entry.prev = 0
+		}
+		entry.curr = 0
+		entry.windowStart = entry.windowStart.Add(elapsed / window * window)
+	}
+	elapsed := now.Sub(entry.windowStart)
+	weight := 1 - float64(elapsed)/float64(window)
+	estimated := float64(entry.prev)*weight + float64(entry.curr)
+
+	result := RateLimitResult{Limit: rule.Limit}
+	if estimated+1 <= float64(rule.Limit) {
+		entry.curr++
+		estimated++
+		result.Allowed = true
+	} else if entry.curr+1 > rule.Limit {
+		// wait for the next window, in which the current count is weighted down enough
+		next := window - elapsed
+		result.RetryAfter = next + time.Duration(float64(window)*(1-float64(rule.Limit-1)/float64(entry.curr)))
+	} else {
+		// wait for the previous count to be weighted down enough
+		ratio := 1 - float64(rule.Limit-1-entry.curr)/float64(entry.prev)
+		result.RetryAfter = time.Duration(float64(window)*ratio) - elapsed
+	}
+	result.Remaining = rule.Limit - int(math.Ceil(estimated))
+	if result.Remaining < 0 {
+		result.Remaining = 0
+	}
+	// the current count influences until the end of the next window, the previous one until the end of the current
+	switch {
+	case entry.curr > 0:
+		result.Reset = 2*window - elapsed
+	case entry.prev > 0:
+		result.Reset = window - elapsed
+	default:
+		result.Reset = 0
+	}
+	entry.expire = entry.windowStart.Add(2 * window)
+	return result
+}
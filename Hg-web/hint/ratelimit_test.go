@@ -0,0 +1,111 @@
+package hint
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (f *fakeClock) now() time.Time          { return f.t }
+func (f *fakeClock) advance(d time.Duration) { f.t = f.t.Add(d) }
+
+func TestTokenBucket(t *testing.T) {
+	ctx := context.Background()
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	store := NewMemoryRateLimitStore()
+	store.now = clock.now
+	rule := RateLimitRule{Algorithm: TokenBucket, Limit: 10, Window: time.Second, Burst: 3}
+
+	for i := 0; i < 3; i++ {
+		if r, _ := store.Take(ctx, "k", rule); !r.Allowed || r.Remaining != 2-i {
+			t.Fatalf("request %d: unexpected result %+v", i, r)
+		}
+	}
+	r, _ := store.Take(ctx, "k", rule)
+	if r.Allowed || r.RetryAfter != 100*time.Millisecond {
+		t.Fatalf("burst exceeded: unexpected result %+v", r)
+	}
+	clock.advance(100 * time.Millisecond)
+	if r, _ = store.Take(ctx, "k", rule); !r.Allowed {
+		t.Fatalf("token is not refilled: %+v", r)
+	}
+	if r, _ = store.Take(ctx, "other", rule); !r.Allowed || r.Remaining != 2 {
+		t.Fatalf("keys are not isolated: %+v", r)
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	ctx := context.Background()
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	store := NewMemoryRateLimitStore()
+	store.now = clock.now
+	rule := RateLimitRule{Algorithm: SlidingWindow, Limit: 4, Window: time.Minute}
+
+	for i := 0; i < 4; i++ {
+		if r, _ := store.Take(ctx, "k", rule); !r.Allowed {
+			t.Fatalf("request %d is limited: %+v", i, r)
+		}
+	}
+	if r, _ := store.Take(ctx, "k", rule); r.Allowed || r.RetryAfter != 75*time.Second {
+		t.Fatalf("limit exceeded: unexpected result %+v", r)
+	}
+	// 4 requests of the previous window are weighted by 1/2 at the middle of the next window
+	clock.advance(90 * time.Second)
+	for i := 0; i < 2; i++ {
+		if r, _ := store.Take(ctx, "k", rule); !r.Allowed {
+			t.Fatalf("request %d of next window is limited: %+v", i, r)
+		}
+	}
+	if r, _ := store.Take(ctx, "k", rule); r.Allowed {
+		t.Fatalf("previous window is not counted: %+v", r)
+	}
+	clock.advance(2 * time.Minute)
+	if r, _ := store.Take(ctx, "k", rule); !r.Allowed || r.Remaining != 3 {
+		t.Fatalf("window is not reset: %+v", r)
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	e := New()
+	e.Use(RateLimit(RateLimitConfig{
+		Rule:    RateLimitRule{Algorithm: TokenBucket, Limit: 1, Window: time.Hour},
+		KeyFunc: RateLimitByKeys(RateLimitByRoute, RateLimitByHeader("X-API-Key")),
+	}))
+	e.GET("/a", func(c *Context) { c.String(http.StatusOK, "a") })
+	e.GET("/b", func(c *Context) { c.String(http.StatusOK, "b") })
+
+	request := func(path, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request("/a", "k1")
+	if w.Code != http.StatusOK || w.Header().Get("RateLimit-Limit") != "1" || w.Header().Get("RateLimit-Remaining") != "0" ||
+		w.Header().Get("RateLimit-Policy") != "1;w=3600" {
+		t.Fatalf("unexpected response %d %v", w.Code, w.Header())
+	}
+	w = request("/a", "k1")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "3600" {
+		t.Fatalf("expect 429 with Retry-After, got %d %v", w.Code, w.Header())
+	}
+	if w = request("/b", "k1"); w.Code != http.StatusOK {
+		t.Fatalf("routes are not isolated, got %d", w.Code)
+	}
+	if w = request("/a", "k2"); w.Code != http.StatusOK {
+		t.Fatalf("keys are not isolated, got %d", w.Code)
+	}
+	for i := 0; i < 3; i++ {
+		if w = request("/a", ""); w.Code != http.StatusOK || w.Header().Get("RateLimit-Limit") != "" {
+			t.Fatalf("requests without key should not be limited, got %d", w.Code)
+		}
+	}
+}
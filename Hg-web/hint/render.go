@@ -0,0 +1,301 @@
+package hint
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+)
+
+// Render 负责把数据按某种格式写入响应，Context.JSON/XML/YAML 等方法都通过 Context.Render 实现，
+// 自定义格式只需要实现 Render 接口，e.g. c.Render(http.StatusOK, MsgPackRender{Data: obj})
+// SecureJSON: 数据是数组时添加前缀(默认 while(1);)，防止 JSON 劫持。
+// JSONP: 输出 /**/callback(data);，callback 只允许 JavaScript 标识符，防止注入脚本。
+// PureJSON: 不转义 HTML 字符，<b> 不会被转义为 \u003cb\u003e。
+
+// Render is the interface of response renderers
+type Render interface {
+	// Render writes data with custom ContentType
+	Render(w http.ResponseWriter) error
+	// WriteContentType writes custom ContentType
+	WriteContentType(w http.ResponseWriter)
+}
+
+const defaultSecureJSONPrefix = "while(1);"
+
+var (
+	plainContentType      = []string{MIMEPlain}
+	jsonContentType       = []string{MIMEJSON}
+	jsonpContentType      = []string{"application/javascript; charset=utf-8"}
+	htmlContentType       = []string{MIMEHTML}
+	xmlContentType        = []string{MIMEXML + "; charset=utf-8"}
+	yamlContentType       = []string{MIMEYAML + "; charset=utf-8"}
+	protobufContentType   = []string{MIMEPROTOBUF}
+	errInvalidJSONPName   = errors.New("render: invalid JSONP callback")
+	errInvalidProtoBufMsg = errors.New("render: data is not a proto.Message")
+)
+
+func writeContentType(w http.ResponseWriter, value []string) {
+	header := w.Header()
+	if len(header["Content-Type"]) == 0 {
+		header["Content-Type"] = value
+	}
+}
+
+// StringRender renders fmt.Sprintf(Format, Data...) as plain text
+type StringRender struct {
+	Format string
+	Data   []interface{}
+}
+
+func (r StringRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	if len(r.Data) == 0 {
+		_, err := w.Write([]byte(r.Format))
+		return err
+	}
+	_, err := fmt.Fprintf(w, r.Format, r.Data...)
+	return err
+}
+
+func (r StringRender) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, plainContentType)
+}
+
+// DataRender renders raw bytes, Content-Type is not set when ContentType is empty
+type DataRender struct {
+	ContentType string
+	Data        []byte
+}
+
+func (r DataRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	_, err := w.Write(r.Data)
+	return err
+}
+
+func (r DataRender) WriteContentType(w http.ResponseWriter) {
+	if r.ContentType != "" {
+		writeContentType(w, []string{r.ContentType})
+	}
+}
+
+// HTMLRender renders the template named Name
+type HTMLRender struct {
+	Template *template.Template
+	Name     string
+	Data     interface{}
+}
+
+func (r HTMLRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	if r.Template == nil {
+		return errors.New("render: html templates are not loaded, call LoadHTMLGlob first")
+	}
+	return r.Template.ExecuteTemplate(w, r.Name, r.Data)
+}
+
+func (r HTMLRender) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, htmlContentType)
+}
+
+// JSONRender renders Data as JSON
+type JSONRender struct {
+	Data interface{}
+}
+
+func (r JSONRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	b, err := json.Marshal(r.Data)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+func (r JSONRender) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, jsonContentType)
+}
+
+// IndentedJSONRender renders Data as pretty JSON
+type IndentedJSONRender struct {
+	Data interface{}
+}
+
+func (r IndentedJSONRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	b, err := json.MarshalIndent(r.Data, "", "    ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+func (r IndentedJSONRender) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, jsonContentType)
+}
+
+// PureJSONRender renders Data as JSON without escaping HTML characters
+type PureJSONRender struct {
+	Data interface{}
+}
+
+func (r PureJSONRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(r.Data); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (r PureJSONRender) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, jsonContentType)
+}
+
+// SecureJSONRender renders Data as JSON, Prefix is prepended when Data is a JSON array
+type SecureJSONRender struct {
+	Prefix string
+	Data   interface{}
+}
+
+func (r SecureJSONRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	b, err := json.Marshal(r.Data)
+	if err != nil {
+		return err
+	}
+	if bytes.HasPrefix(b, []byte("[")) && bytes.HasSuffix(b, []byte("]")) {
+		b = append([]byte(r.Prefix), b...)
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+func (r SecureJSONRender) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, jsonContentType)
+}
+
+// JSONPRender renders Data as /**/Callback(json); or JSON when Callback is empty
+type JSONPRender struct {
+	Callback string
+	Data     interface{}
+}
+
+func (r JSONPRender) Render(w http.ResponseWriter) error {
+	if r.Callback == "" {
+		return JSONRender{Data: r.Data}.Render(w)
+	}
+	if !validJSONPCallback(r.Callback) {
+		return errInvalidJSONPName
+	}
+	r.WriteContentType(w)
+	b, err := json.Marshal(r.Data)
+	if err != nil {
+		return err
+	}
+	// the comment prevents the response from being treated as other content types, e.g. Rosetta Flash
+	_, err = fmt.Fprintf(w, "/**/%s(%s);", r.Callback, b)
+	return err
+}
+
+func (r JSONPRender) WriteContentType(w http.ResponseWriter) {
+	if r.Callback == "" {
+		writeContentType(w, jsonContentType)
+		return
+	}
+	writeContentType(w, jsonpContentType)
+}
+
+// validJSONPCallback accepts the identifiers and property accessors, e.g. jQuery123_cb, app.callbacks.done
+func validJSONPCallback(callback string) bool {
+	if len(callback) > 128 {
+		return false
+	}
+	start := true
+	for i := 0; i < len(callback); i++ {
+		ch := callback[i]
+		switch {
+		case ch == '_' || ch == '$' || 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z':
+			start = false
+		case '0' <= ch && ch <= '9' && !start:
+		case ch == '.' && !start:
+			start = true
+		default:
+			return false
+		}
+	}
+	return !start
+}
+
+// XMLRender renders Data as XML
+type XMLRender struct {
+	Data interface{}
+}
+
+func (r XMLRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	b, err := xml.Marshal(r.Data)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+func (r XMLRender) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, xmlContentType)
+}
+
+// YAMLRender renders Data as YAML
+type YAMLRender struct {
+	Data interface{}
+}
+
+func (r YAMLRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	b, err := yaml.Marshal(r.Data)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+func (r YAMLRender) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, yamlContentType)
+}
+
+// ProtoBufRender renders Data as protobuf, Data must be a proto.Message
+type ProtoBufRender struct {
+	Data interface{}
+}
+
+func (r ProtoBufRender) Render(w http.ResponseWriter) error {
+	msg, ok := r.Data.(proto.Message)
+	if !ok {
+		return errInvalidProtoBufMsg
+	}
+	r.WriteContentType(w)
+	b, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+func (r ProtoBufRender) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, protobufContentType)
+}
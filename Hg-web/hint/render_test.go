@@ -0,0 +1,93 @@
+package hint
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type renderUser struct {
+	Name string `json:"name" xml:"name" yaml:"name"`
+}
+
+func TestRenderers(t *testing.T) {
+	e := New()
+	e.GET("/xml", func(c *Context) { c.XML(http.StatusOK, renderUser{Name: "hg"}) })
+	e.GET("/yaml", func(c *Context) { c.YAML(http.StatusOK, H{"name": "hg"}) })
+	e.GET("/indented", func(c *Context) { c.IndentedJSON(http.StatusOK, H{"name": "hg"}) })
+	e.GET("/pure", func(c *Context) { c.PureJSON(http.StatusOK, H{"html": "<b>"}) })
+	e.GET("/json", func(c *Context) { c.JSON(http.StatusOK, H{"html": "<b>"}) })
+	e.GET("/secure", func(c *Context) { c.SecureJSON(http.StatusOK, []string{"a"}) })
+	e.GET("/jsonp", func(c *Context) { c.JSONP(http.StatusOK, H{"name": "hg"}) })
+	e.GET("/protobuf", func(c *Context) { c.ProtoBuf(http.StatusOK, wrapperspb.String("hg")) })
+	e.GET("/invalid", func(c *Context) { c.ProtoBuf(http.StatusOK, H{}) })
+	e.GET("/nocontent", func(c *Context) { c.JSON(http.StatusNoContent, H{"name": "hg"}) })
+
+	tests := []struct {
+		path        string
+		code        int
+		contentType string
+		body        string
+	}{
+		{"/xml", 200, "application/xml; charset=utf-8", "<renderUser><name>hg</name></renderUser>"},
+		{"/yaml", 200, "application/yaml; charset=utf-8", "name: hg\n"},
+		{"/indented", 200, MIMEJSON, "{\n    \"name\": \"hg\"\n}"},
+		{"/pure", 200, MIMEJSON, "{\"html\":\"<b>\"}\n"},
+		{"/json", 200, MIMEJSON, "{\"html\":\"\\u003cb\\u003e\"}\n"},
+		{"/secure", 200, MIMEJSON, "while(1);[\"a\"]"},
+		{"/jsonp?callback=app.done", 200, "application/javascript; charset=utf-8", "/**/app.done({\"name\":\"hg\"});"},
+		{"/jsonp", 200, MIMEJSON, "{\"name\":\"hg\"}\n"},
+		{"/jsonp?callback=alert(1)//", 400, MIMEJSON, "{\"message\":\"render: invalid JSONP callback\"}\n"},
+		{"/invalid", 500, MIMEJSON, "{\"message\":\"render: data is not a proto.Message\"}\n"},
+		{"/nocontent", 204, MIMEJSON, ""},
+	}
+	for _, tt := range tests {
+		w := performRequest(e, http.MethodGet, tt.path)
+		if w.Code != tt.code || w.Header().Get("Content-Type") != tt.contentType || w.Body.String() != tt.body {
+			t.Errorf("%s: unexpected response %d %q %q", tt.path, w.Code, w.Header().Get("Content-Type"), w.Body.String())
+		}
+	}
+
+	w := performRequest(e, http.MethodGet, "/protobuf")
+	var msg wrapperspb.StringValue
+	if err := proto.Unmarshal(w.Body.Bytes(), &msg); err != nil || msg.GetValue() != "hg" {
+		t.Fatalf("unexpected protobuf %v %v", msg.GetValue(), err)
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	e := New()
+	e.GET("/user", func(c *Context) {
+		c.Negotiate(http.StatusOK, Negotiate{
+			Offered:      []string{MIMEJSON, MIMEPROTOBUF, MIMEXML},
+			Data:         renderUser{Name: "hg"},
+			ProtoBufData: wrapperspb.String("hg"),
+		})
+	})
+	tests := []struct {
+		accept      string
+		code        int
+		contentType string
+	}{
+		{"", http.StatusOK, MIMEJSON},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", http.StatusOK, "application/xml; charset=utf-8"},
+		{"application/x-protobuf", http.StatusOK, MIMEPROTOBUF},
+		{"application/*;q=0.5, application/json;q=0.1", http.StatusOK, MIMEPROTOBUF},
+		{"*/*", http.StatusOK, MIMEJSON},
+		{"image/png", http.StatusNotAcceptable, MIMEJSON},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/user", nil)
+		if tt.accept != "" {
+			req.Header.Set("Accept", tt.accept)
+		}
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		if w.Code != tt.code || w.Header().Get("Content-Type") != tt.contentType {
+			t.Errorf("%q: unexpected response %d %q", tt.accept, w.Code, w.Header().Get("Content-Type"))
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package hint
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+)
+
+// http.ResponseWriter 写出响应后无法再读取状态码和大小，handler 直接写 c.Writer 或者交给 http.FileServer 时，
+// 中间件(例如 Logger)就无从得知响应的状态。
+// 这里对 http.ResponseWriter 做一层包装，记录状态码、写出的字节数以及响应头是否已经发送。
+// 状态码在第一次写 body 前才真正发送(WriteHeaderNow)，因此在此之前可以多次调用 Status 修改，不会产生 superfluous WriteHeader 警告。
+
+const (
+	noWritten     = -1
+	defaultStatus = http.StatusOK
+)
+
+// ResponseWriter is the instrumented http.ResponseWriter used by Context
+type ResponseWriter interface {
+	http.ResponseWriter
+	http.Hijacker
+	http.Flusher
+	http.CloseNotifier
+
+	// Status returns the HTTP response status code of the current request
+	Status() int
+
+	// Size returns the number of bytes already written into the response http body
+	Size() int
+
+	// WriteString writes the string into the response body
+	WriteString(string) (int, error)
+
+	// Written returns true if the response headers were already sent
+	Written() bool
+
+	// WriteHeaderNow forces to write the http header (status code + headers)
+	WriteHeaderNow()
+
+	// Pusher returns http.Pusher for server push, nil when it's not supported (e.g. HTTP/1.1)
+	Pusher() http.Pusher
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	size   int
+	status int
+}
+
+var _ ResponseWriter = (*responseWriter)(nil)
+
+func (w *responseWriter) reset(writer http.ResponseWriter) {
+	w.ResponseWriter = writer
+	w.size = noWritten
+	w.status = defaultStatus
+}
+
+// Unwrap returns the origin http.ResponseWriter, used by http.ResponseController
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// WriteHeader only records the status code, the header is sent by the first Write or WriteHeaderNow
+// it is ignored when the header has been sent
+func (w *responseWriter) WriteHeader(code int) {
+	if code > 0 && w.status != code && !w.Written() {
+		w.status = code
+	}
+}
+
+func (w *responseWriter) WriteHeaderNow() {
+	if !w.Written() {
+		w.size = 0
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+}
+
+func (w *responseWriter) Write(data []byte) (n int, err error) {
+	w.WriteHeaderNow()
+	n, err = w.ResponseWriter.Write(data)
+	w.size += n
+	return
+}
+
+func (w *responseWriter) WriteString(s string) (n int, err error) {
+	w.WriteHeaderNow()
+	n, err = io.WriteString(w.ResponseWriter, s)
+	w.size += n
+	return
+}
+
+func (w *responseWriter) Status() int {
+	return w.status
+}
+
+func (w *responseWriter) Size() int {
+	return w.size
+}
+
+func (w *responseWriter) Written() bool {
+	return w.size != noWritten
+}
+
+// Hijack implements the http.Hijacker interface, the response is regarded as written after hijacking succeeds
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the ResponseWriter doesn't support the Hijacker interface")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err == nil && w.size < 0 {
+		w.size = 0
+	}
+	return conn, rw, err
+}
+
+// CloseNotify implements the http.CloseNotifier interface
+// the channel never receives when the origin ResponseWriter doesn't support it, use Req.Context().Done() instead
+func (w *responseWriter) CloseNotify() <-chan bool {
+	if notifier, ok := w.ResponseWriter.(http.CloseNotifier); ok {
+		return notifier.CloseNotify()
+	}
+	return make(chan bool)
+}
+
+// Flush implements the http.Flusher interface, the header is sent before flushing
+func (w *responseWriter) Flush() {
+	w.WriteHeaderNow()
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *responseWriter) Pusher() http.Pusher {
+	if pusher, ok := w.ResponseWriter.(http.Pusher); ok {
+		return pusher
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package hint
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResponseWriterTracksStatus(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := newContext(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if c.Writer.Written() || c.Writer.Status() != http.StatusOK || c.Writer.Size() != -1 {
+		t.Fatal("unexpected initial state")
+	}
+
+	// status can be changed before the body is written
+	c.Status(http.StatusCreated)
+	c.Status(http.StatusAccepted)
+	if w.Code != http.StatusOK || w.Flushed || c.Writer.Written() {
+		t.Fatal("header should not be sent before writing the body")
+	}
+
+	n, _ := c.Writer.Write([]byte("hello"))
+	m, _ := c.Writer.WriteString(" hint")
+	if !c.Writer.Written() || c.Writer.Size() != n+m {
+		t.Fatalf("expect size %d, got %d", n+m, c.Writer.Size())
+	}
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expect 202, got %d", w.Code)
+	}
+
+	// status can't be changed after the header is sent
+	c.Status(http.StatusInternalServerError)
+	if c.Writer.Status() != http.StatusAccepted {
+		t.Fatalf("status should not be changed after written, got %d", c.Writer.Status())
+	}
+	// the deprecated field follows the status that is actually sent
+	if c.StatusCode != http.StatusAccepted {
+		t.Fatalf("expect StatusCode 202, got %d", c.StatusCode)
+	}
+}
+
+func TestResponseWriterDirectWrite(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte("hint"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var status, size int
+	e := New()
+	e.Use(func(c *Context) {
+		c.Next()
+		status, size = c.Writer.Status(), c.Writer.Size()
+	})
+	e.Static("/assets", dir)
+	e.GET("/raw", func(c *Context) {
+		c.Writer.WriteHeader(http.StatusTeapot)
+		_, _ = io.WriteString(c.Writer, "tea")
+	})
+	e.GET("/nothing", func(c *Context) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+	})
+
+	tests := []struct {
+		path   string
+		status int
+		size   int
+	}{
+		{"/assets/file.txt", http.StatusOK, 4},
+		{"/assets/missing.txt", http.StatusNotFound, -1},
+		{"/raw", http.StatusTeapot, 3},
+		{"/nothing", http.StatusUnauthorized, -1},
+	}
+	for _, tt := range tests {
+		w := performRequest(e, http.MethodGet, tt.path)
+		if status != tt.status || size != tt.size {
+			t.Errorf("%s: expect [%d] %d bytes, got [%d] %d bytes", tt.path, tt.status, tt.size, status, size)
+		}
+		// header is sent at the end of the request even when nothing is written
+		if w.Code != tt.status {
+			t.Errorf("%s: expect response %d, got %d", tt.path, tt.status, w.Code)
+		}
+	}
+}
+
+func TestResponseWriterPassthrough(t *testing.T) {
+	e := New()
+	e.GET("/flush", func(c *Context) {
+		c.Status(http.StatusAccepted)
+		c.Writer.Flush()
+		if !c.Writer.Written() {
+			t.Error("header should be sent by Flush")
+		}
+		if c.Writer.Pusher() != nil {
+			t.Error("HTTP/1.1 doesn't support server push")
+		}
+	})
+	e.GET("/hijack", func(c *Context) {
+		conn, buf, err := c.Writer.Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		_, _ = buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 6\r\nConnection: close\r\n\r\nhijack")
+		_ = buf.Flush()
+	})
+	ts := httptest.NewServer(e)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/flush")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expect 202, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Get(ts.URL + "/hijack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(bufio.NewReader(resp.Body))
+	resp.Body.Close()
+	if string(body) != "hijack" {
+		t.Fatalf("unexpected body %q", body)
+	}
+}
+
+// failedHijacker is a ResponseWriter whose Hijack always fails
+type failedHijacker struct {
+	*httptest.ResponseRecorder
+}
+
+func (failedHijacker) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, http.ErrHijacked
+}
+
+func TestResponseWriterFailedHijack(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := newContext(failedHijacker{w}, httptest.NewRequest(http.MethodGet, "/", nil))
+	if _, _, err := c.Writer.Hijack(); err == nil {
+		t.Fatal("expect hijack error")
+	}
+	// the connection is not taken over, so the response can still be written
+	if c.Writer.Written() {
+		t.Fatal("response should not be regarded as written after failed hijack")
+	}
+	c.String(http.StatusServiceUnavailable, "no upgrade")
+	if w.Code != http.StatusServiceUnavailable || w.Body.String() != "no upgrade" {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+	}
+}
@@ -1,26 +1,27 @@
 package hint
 
 import (
+	"fmt"
 	"log"
 	"net/http"
+	"sort"
 	"strings"
 )
 
 // 将路由相关的方法和结构提取出来，方便对 router 的功能进行增强
-// 例如，提供动态路由的支持(trie树实现)
-// 使用 roots 来存储每种请求方式的Trie树根节点。使用 handlers 存储每种请求方式的HandlerFunc。
+// 例如，提供动态路由的支持(radix tree实现)
+// 使用 roots 来存储每种请求方式的前缀树根节点，HandlerFunc 直接存储在前缀树节点上。
 
 // router struct
 type router struct {
-	roots    map[string]*trieNode   // roots key e.g. roots['GET'] roots['POST']
-	handlers map[string]HandlerFunc // handlers key e.g. handlers['GET-/p/:lang/doc'], handlers['POST-/p/book']
+	roots     map[string]*node // roots key e.g. roots['GET'] roots['POST']
+	maxParams int              // max count of wildcards of all patterns, used to allocate Params once
 }
 
 // constructor of Router
 func newRouter() *router {
 	return &router{
-		roots:    make(map[string]*trieNode),
-		handlers: make(map[string]HandlerFunc),
+		roots: make(map[string]*node),
 	}
 }
 
@@ -39,63 +40,147 @@ func parsePattern(pattern string) []string {
 	return parts
 }
 
+// validatePattern panics when p contains an unnamed param or a catch-all not at the end
+// e.g. /p/: and /p/*filepath/doc are invalid
+func validatePattern(p string) {
+	ss := strings.Split(p, "/")
+	for i, item := range ss {
+		if item == "" {
+			continue
+		}
+		if item == ":" {
+			panic(fmt.Sprintf("param must be named with a non-empty name in route '%s'", p))
+		}
+		if item[0] == '*' && strings.Join(ss[i+1:], "") != "" {
+			panic(fmt.Sprintf("catch-all is only allowed at the end of route '%s'", p))
+		}
+	}
+}
+
 // handle router
+// handlers of matched router already contains the group middlewares
+// otherwise middlewares of the group with the longest prefix are applied to 404/405 handler
 func (r *router) handle(c *Context) {
-	n, params := r.getRoute(c.Method, c.Path)
+	n := r.getValue(c.Method, c.Path, &c.Params)
 	if n != nil {
-		c.Params = params
-		key := c.Method + "-" + n.pattern
-		c.handlers = append(c.handlers, r.handlers[key])
+		c.fullPath = n.pattern
+		c.handlers = n.handlers
+	} else if allow := r.allowed(c.Method, c.Path); allow != "" {
+		// path exists in other methods' tree, answer OPTIONS automatically, otherwise 405
+		if c.Method == http.MethodOptions {
+			c.handlers = c.e.matchGroup(c.Path).combineHandlers([]HandlerFunc{func(c *Context) {
+				c.SetHeader("Allow", allow)
+				c.Status(http.StatusNoContent)
+			}})
+		} else {
+			c.handlers = c.e.matchGroup(c.Path).combineHandlers([]HandlerFunc{func(c *Context) {
+				c.SetHeader("Allow", allow)
+				c.String(http.StatusMethodNotAllowed, "405 Method Not Allowed: %s\n", c.Method)
+			}})
+		}
 	} else {
-		c.handlers = append(c.handlers, func(c *Context) {
+		c.handlers = c.e.matchGroup(c.Path).combineHandlers([]HandlerFunc{func(c *Context) {
 			c.String(http.StatusNotFound, "404 Not Found: %s\n", c.Path)
-		})
+		}})
 	}
 	c.Next()
 }
 
+// allowed returns the value of "Allow" header for path p, "" when no other method matches p
+// e.g. GET, OPTIONS, POST
+func (r *router) allowed(m string, p string) string {
+	methods := make([]string, 0, len(r.roots)+1)
+	hasOptions := false
+	for method := range r.roots {
+		if method == m {
+			continue
+		}
+		if n, _ := r.getRoute(method, p); n != nil {
+			methods = append(methods, method)
+			hasOptions = hasOptions || method == http.MethodOptions
+		}
+	}
+	if len(methods) == 0 {
+		return ""
+	}
+	// OPTIONS is always allowed since it is answered automatically
+	if !hasOptions {
+		methods = append(methods, http.MethodOptions)
+	}
+	sort.Strings(methods)
+	return strings.Join(methods, ", ")
+}
+
 // inside func for users to add router
 // m -> http method(get/post)
 // p -> full path(pattern)
-// h -> handler func
+// handlers -> full handler chain, middlewares included
 // roots key e.g. roots['GET'] roots['POST']
-// handlers key e.g. handlers['GET-/p/:lang/doc'], handlers['POST-/p/book']
-func (r *router) addRouter(m string, p string, h HandlerFunc) {
+// p is cleaned before inserting, e.g. /p//book/ -> /p/book
+func (r *router) addRouter(m string, p string, handlers []HandlerFunc) {
 	log.Printf("Route %4s - %s", m, p)
+	validatePattern(p)
 	parts := parsePattern(p)
+	pattern := "/" + strings.Join(parts, "/")
 
-	key := m + "-" + p
-	_, ok := r.roots[m]
+	root, ok := r.roots[m]
 	if !ok {
-		r.roots[m] = &trieNode{}
+		root = &node{}
+		r.roots[m] = root
+	}
+	root.insert(pattern, pattern, handlers)
+
+	wildcards := 0
+	for _, part := range parts {
+		if part[0] == ':' || part[0] == '*' {
+			wildcards++
+		}
+	}
+	if wildcards > r.maxParams {
+		r.maxParams = wildcards
 	}
-	r.roots[m].insert(p, parts, 0)
-	r.handlers[key] = h
 }
 
-// getRoute returns a map that key is the suffix of ":" or "*",value is the same part's pattern
-// e.g. /p/go/doc -> /p/:lang/doc -> {lang: "go"}
-// e.g. /static/css/hb.css -> /static/*filepath -> {filepath: "css/hb.css"}
-func (r *router) getRoute(m string, p string) (*trieNode, map[string]string) {
-	searchParts := parsePattern(p)
-	params := make(map[string]string)
+// getValue returns the node matched by path p, the values of wildcards are appended to params
+// params is reset and reused, so lookup costs zero allocations when params has enough capacity
+// e.g. /p/go/doc -> /p/:lang/doc -> [{lang go}]
+// e.g. /static/css/hb.css -> /static/*filepath -> [{filepath css/hb.css}]
+func (r *router) getValue(m string, p string, params *Params) *node {
+	*params = (*params)[:0]
 	root, ok := r.roots[m]
 	if !ok {
-		return nil, nil
+		return nil
 	}
-	n := root.search(searchParts, 0)
-	if n != nil {
-		parts := parsePattern(n.pattern)
-		for index, part := range parts {
-			if part[0] == ':' {
-				params[part[1:]] = searchParts[index]
-			}
-			if part[0] == '*' && len(part) > 1 {
-				params[part[1:]] = strings.Join(searchParts[index:], "/")
-				break
-			}
+	// empty segments are ignored as the pattern has been cleaned, e.g. /p//book matches /p/book
+	if strings.Contains(p, "//") {
+		p = collapseSlashes(p)
+	}
+	// trailing slash is ignored as the pattern has been cleaned
+	if len(p) > 1 && p[len(p)-1] == '/' {
+		p = p[:len(p)-1]
+	}
+	return root.search(p, params)
+}
+
+// collapseSlashes replaces the repeated slashes of p with one slash
+func collapseSlashes(p string) string {
+	var sb strings.Builder
+	sb.Grow(len(p))
+	for i := 0; i < len(p); i++ {
+		if p[i] == '/' && i > 0 && p[i-1] == '/' {
+			continue
 		}
-		return n, params
+		sb.WriteByte(p[i])
+	}
+	return sb.String()
+}
+
+// getRoute returns the matched node and a new Params, used when the allocation doesn't matter
+func (r *router) getRoute(m string, p string) (*node, Params) {
+	params := make(Params, 0, r.maxParams)
+	n := r.getValue(m, p, &params)
+	if n == nil {
+		return nil, nil
 	}
-	return nil, nil
+	return n, params
 }
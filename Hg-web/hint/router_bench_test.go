@@ -0,0 +1,563 @@
+package hint
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// 与原先的 Trie 路由实现进行对比的基准测试，路由表使用 GitHub API (约200个路由)。
+// go test -run ^$ -bench . -benchmem
+
+type route struct {
+	method string
+	path   string
+}
+
+// githubAPI is the route set of GitHub API v3
+var githubAPI = []route{
+	// OAuth Authorizations
+	{"GET", "/authorizations"},
+	{"GET", "/authorizations/:id"},
+	{"POST", "/authorizations"},
+	{"PUT", "/authorizations/clients/:client_id"},
+	{"PATCH", "/authorizations/:id"},
+	{"DELETE", "/authorizations/:id"},
+	{"GET", "/applications/:client_id/tokens/:access_token"},
+	{"DELETE", "/applications/:client_id/tokens"},
+	{"DELETE", "/applications/:client_id/tokens/:access_token"},
+
+	// Activity
+	{"GET", "/events"},
+	{"GET", "/repos/:owner/:repo/events"},
+	{"GET", "/networks/:owner/:repo/events"},
+	{"GET", "/orgs/:org/events"},
+	{"GET", "/users/:user/received_events"},
+	{"GET", "/users/:user/received_events/public"},
+	{"GET", "/users/:user/events"},
+	{"GET", "/users/:user/events/public"},
+	{"GET", "/users/:user/events/orgs/:org"},
+	{"GET", "/feeds"},
+	{"GET", "/notifications"},
+	{"GET", "/repos/:owner/:repo/notifications"},
+	{"PUT", "/notifications"},
+	{"PUT", "/repos/:owner/:repo/notifications"},
+	{"GET", "/notifications/threads/:id"},
+	{"PATCH", "/notifications/threads/:id"},
+	{"GET", "/notifications/threads/:id/subscription"},
+	{"PUT", "/notifications/threads/:id/subscription"},
+	{"DELETE", "/notifications/threads/:id/subscription"},
+	{"GET", "/repos/:owner/:repo/stargazers"},
+	{"GET", "/users/:user/starred"},
+	{"GET", "/user/starred"},
+	{"GET", "/user/starred/:owner/:repo"},
+	{"PUT", "/user/starred/:owner/:repo"},
+	{"DELETE", "/user/starred/:owner/:repo"},
+	{"GET", "/repos/:owner/:repo/subscribers"},
+	{"GET", "/users/:user/subscriptions"},
+	{"GET", "/user/subscriptions"},
+	{"GET", "/repos/:owner/:repo/subscription"},
+	{"PUT", "/repos/:owner/:repo/subscription"},
+	{"DELETE", "/repos/:owner/:repo/subscription"},
+	{"GET", "/user/subscriptions/:owner/:repo"},
+	{"PUT", "/user/subscriptions/:owner/:repo"},
+	{"DELETE", "/user/subscriptions/:owner/:repo"},
+
+	// Gists
+	{"GET", "/users/:user/gists"},
+	{"GET", "/gists"},
+	{"GET", "/gists/public"},
+	{"GET", "/gists/starred"},
+	{"GET", "/gists/:id"},
+	{"POST", "/gists"},
+	{"PATCH", "/gists/:id"},
+	{"PUT", "/gists/:id/star"},
+	{"DELETE", "/gists/:id/star"},
+	{"GET", "/gists/:id/star"},
+	{"POST", "/gists/:id/forks"},
+	{"DELETE", "/gists/:id"},
+
+	// Git Data
+	{"GET", "/repos/:owner/:repo/git/blobs/:sha"},
+	{"POST", "/repos/:owner/:repo/git/blobs"},
+	{"GET", "/repos/:owner/:repo/git/commits/:sha"},
+	{"POST", "/repos/:owner/:repo/git/commits"},
+	{"GET", "/repos/:owner/:repo/git/refs/*ref"},
+	{"GET", "/repos/:owner/:repo/git/refs"},
+	{"POST", "/repos/:owner/:repo/git/refs"},
+	{"PATCH", "/repos/:owner/:repo/git/refs/*ref"},
+	{"DELETE", "/repos/:owner/:repo/git/refs/*ref"},
+	{"GET", "/repos/:owner/:repo/git/tags/:sha"},
+	{"POST", "/repos/:owner/:repo/git/tags"},
+	{"GET", "/repos/:owner/:repo/git/trees/:sha"},
+	{"POST", "/repos/:owner/:repo/git/trees"},
+
+	// Issues
+	{"GET", "/issues"},
+	{"GET", "/user/issues"},
+	{"GET", "/orgs/:org/issues"},
+	{"GET", "/repos/:owner/:repo/issues"},
+	{"GET", "/repos/:owner/:repo/issues/:number"},
+	{"POST", "/repos/:owner/:repo/issues"},
+	{"PATCH", "/repos/:owner/:repo/issues/:number"},
+	{"GET", "/repos/:owner/:repo/assignees"},
+	{"GET", "/repos/:owner/:repo/assignees/:assignee"},
+	{"GET", "/repos/:owner/:repo/issues/:number/comments"},
+	{"GET", "/repos/:owner/:repo/issues/comments"},
+	{"GET", "/repos/:owner/:repo/issues/comments/:id"},
+	{"POST", "/repos/:owner/:repo/issues/:number/comments"},
+	{"PATCH", "/repos/:owner/:repo/issues/comments/:id"},
+	{"DELETE", "/repos/:owner/:repo/issues/comments/:id"},
+	{"GET", "/repos/:owner/:repo/issues/:number/events"},
+	{"GET", "/repos/:owner/:repo/issues/events"},
+	{"GET", "/repos/:owner/:repo/issues/events/:id"},
+	{"GET", "/repos/:owner/:repo/labels"},
+	{"GET", "/repos/:owner/:repo/labels/:name"},
+	{"POST", "/repos/:owner/:repo/labels"},
+	{"PATCH", "/repos/:owner/:repo/labels/:name"},
+	{"DELETE", "/repos/:owner/:repo/labels/:name"},
+	{"GET", "/repos/:owner/:repo/issues/:number/labels"},
+	{"POST", "/repos/:owner/:repo/issues/:number/labels"},
+	{"DELETE", "/repos/:owner/:repo/issues/:number/labels/:name"},
+	{"PUT", "/repos/:owner/:repo/issues/:number/labels"},
+	{"DELETE", "/repos/:owner/:repo/issues/:number/labels"},
+	{"GET", "/repos/:owner/:repo/milestones/:number/labels"},
+	{"GET", "/repos/:owner/:repo/milestones"},
+	{"GET", "/repos/:owner/:repo/milestones/:number"},
+	{"POST", "/repos/:owner/:repo/milestones"},
+	{"PATCH", "/repos/:owner/:repo/milestones/:number"},
+	{"DELETE", "/repos/:owner/:repo/milestones/:number"},
+
+	// Miscellaneous
+	{"GET", "/emojis"},
+	{"GET", "/gitignore/templates"},
+	{"GET", "/gitignore/templates/:name"},
+	{"POST", "/markdown"},
+	{"POST", "/markdown/raw"},
+	{"GET", "/meta"},
+	{"GET", "/rate_limit"},
+
+	// Organizations
+	{"GET", "/users/:user/orgs"},
+	{"GET", "/user/orgs"},
+	{"GET", "/orgs/:org"},
+	{"PATCH", "/orgs/:org"},
+	{"GET", "/orgs/:org/members"},
+	{"GET", "/orgs/:org/members/:user"},
+	{"DELETE", "/orgs/:org/members/:user"},
+	{"GET", "/orgs/:org/public_members"},
+	{"GET", "/orgs/:org/public_members/:user"},
+	{"PUT", "/orgs/:org/public_members/:user"},
+	{"DELETE", "/orgs/:org/public_members/:user"},
+	{"GET", "/orgs/:org/teams"},
+	{"GET", "/teams/:id"},
+	{"POST", "/orgs/:org/teams"},
+	{"PATCH", "/teams/:id"},
+	{"DELETE", "/teams/:id"},
+	{"GET", "/teams/:id/members"},
+	{"GET", "/teams/:id/members/:user"},
+	{"PUT", "/teams/:id/members/:user"},
+	{"DELETE", "/teams/:id/members/:user"},
+	{"GET", "/teams/:id/repos"},
+	{"GET", "/teams/:id/repos/:owner/:repo"},
+	{"PUT", "/teams/:id/repos/:owner/:repo"},
+	{"DELETE", "/teams/:id/repos/:owner/:repo"},
+	{"GET", "/user/teams"},
+
+	// Pull Requests
+	{"GET", "/repos/:owner/:repo/pulls"},
+	{"GET", "/repos/:owner/:repo/pulls/:number"},
+	{"POST", "/repos/:owner/:repo/pulls"},
+	{"PATCH", "/repos/:owner/:repo/pulls/:number"},
+	{"GET", "/repos/:owner/:repo/pulls/:number/commits"},
+	{"GET", "/repos/:owner/:repo/pulls/:number/files"},
+	{"GET", "/repos/:owner/:repo/pulls/:number/merge"},
+	{"PUT", "/repos/:owner/:repo/pulls/:number/merge"},
+	{"GET", "/repos/:owner/:repo/pulls/:number/comments"},
+	{"GET", "/repos/:owner/:repo/pulls/comments"},
+	{"GET", "/repos/:owner/:repo/pulls/comments/:number"},
+	{"PUT", "/repos/:owner/:repo/pulls/:number/comments"},
+	{"PATCH", "/repos/:owner/:repo/pulls/comments/:number"},
+	{"DELETE", "/repos/:owner/:repo/pulls/comments/:number"},
+
+	// Repositories
+	{"GET", "/user/repos"},
+	{"GET", "/users/:user/repos"},
+	{"GET", "/orgs/:org/repos"},
+	{"GET", "/repositories"},
+	{"POST", "/user/repos"},
+	{"POST", "/orgs/:org/repos"},
+	{"GET", "/repos/:owner/:repo"},
+	{"PATCH", "/repos/:owner/:repo"},
+	{"GET", "/repos/:owner/:repo/contributors"},
+	{"GET", "/repos/:owner/:repo/languages"},
+	{"GET", "/repos/:owner/:repo/teams"},
+	{"GET", "/repos/:owner/:repo/tags"},
+	{"GET", "/repos/:owner/:repo/branches"},
+	{"GET", "/repos/:owner/:repo/branches/:branch"},
+	{"DELETE", "/repos/:owner/:repo"},
+	{"GET", "/repos/:owner/:repo/collaborators"},
+	{"GET", "/repos/:owner/:repo/collaborators/:user"},
+	{"PUT", "/repos/:owner/:repo/collaborators/:user"},
+	{"DELETE", "/repos/:owner/:repo/collaborators/:user"},
+	{"GET", "/repos/:owner/:repo/comments"},
+	{"GET", "/repos/:owner/:repo/commits/:sha/comments"},
+	{"POST", "/repos/:owner/:repo/commits/:sha/comments"},
+	{"GET", "/repos/:owner/:repo/comments/:id"},
+	{"PATCH", "/repos/:owner/:repo/comments/:id"},
+	{"DELETE", "/repos/:owner/:repo/comments/:id"},
+	{"GET", "/repos/:owner/:repo/commits"},
+	{"GET", "/repos/:owner/:repo/commits/:sha"},
+	{"GET", "/repos/:owner/:repo/readme"},
+	{"GET", "/repos/:owner/:repo/contents/*path"},
+	{"PUT", "/repos/:owner/:repo/contents/*path"},
+	{"DELETE", "/repos/:owner/:repo/contents/*path"},
+	{"GET", "/repos/:owner/:repo/:archive_format/:ref"},
+	{"GET", "/repos/:owner/:repo/keys"},
+	{"GET", "/repos/:owner/:repo/keys/:id"},
+	{"POST", "/repos/:owner/:repo/keys"},
+	{"PATCH", "/repos/:owner/:repo/keys/:id"},
+	{"DELETE", "/repos/:owner/:repo/keys/:id"},
+	{"GET", "/repos/:owner/:repo/downloads"},
+	{"GET", "/repos/:owner/:repo/downloads/:id"},
+	{"DELETE", "/repos/:owner/:repo/downloads/:id"},
+	{"GET", "/repos/:owner/:repo/forks"},
+	{"POST", "/repos/:owner/:repo/forks"},
+	{"GET", "/repos/:owner/:repo/hooks"},
+	{"GET", "/repos/:owner/:repo/hooks/:id"},
+	{"POST", "/repos/:owner/:repo/hooks"},
+	{"PATCH", "/repos/:owner/:repo/hooks/:id"},
+	{"POST", "/repos/:owner/:repo/hooks/:id/tests"},
+	{"DELETE", "/repos/:owner/:repo/hooks/:id"},
+	{"POST", "/repos/:owner/:repo/merges"},
+	{"GET", "/repos/:owner/:repo/releases"},
+	{"GET", "/repos/:owner/:repo/releases/:id"},
+	{"POST", "/repos/:owner/:repo/releases"},
+	{"PATCH", "/repos/:owner/:repo/releases/:id"},
+	{"DELETE", "/repos/:owner/:repo/releases/:id"},
+	{"GET", "/repos/:owner/:repo/releases/:id/assets"},
+	{"GET", "/repos/:owner/:repo/stats/contributors"},
+	{"GET", "/repos/:owner/:repo/stats/commit_activity"},
+	{"GET", "/repos/:owner/:repo/stats/code_frequency"},
+	{"GET", "/repos/:owner/:repo/stats/participation"},
+	{"GET", "/repos/:owner/:repo/stats/punch_card"},
+	{"GET", "/repos/:owner/:repo/statuses/:ref"},
+	{"POST", "/repos/:owner/:repo/statuses/:ref"},
+
+	// Search
+	{"GET", "/search/repositories"},
+	{"GET", "/search/code"},
+	{"GET", "/search/issues"},
+	{"GET", "/search/users"},
+	{"GET", "/legacy/issues/search/:owner/:repository/:state/:keyword"},
+	{"GET", "/legacy/repos/search/:keyword"},
+	{"GET", "/legacy/user/search/:keyword"},
+	{"GET", "/legacy/user/email/:email"},
+
+	// Users
+	{"GET", "/users/:user"},
+	{"GET", "/user"},
+	{"PATCH", "/user"},
+	{"GET", "/users"},
+	{"GET", "/user/emails"},
+	{"POST", "/user/emails"},
+	{"DELETE", "/user/emails"},
+	{"GET", "/users/:user/followers"},
+	{"GET", "/user/followers"},
+	{"GET", "/users/:user/following"},
+	{"GET", "/user/following"},
+	{"GET", "/user/following/:user"},
+	{"GET", "/users/:user/following/:target_user"},
+	{"PUT", "/user/following/:user"},
+	{"DELETE", "/user/following/:user"},
+	{"GET", "/users/:user/keys"},
+	{"GET", "/user/keys"},
+	{"GET", "/user/keys/:id"},
+	{"POST", "/user/keys"},
+	{"PATCH", "/user/keys/:id"},
+	{"DELETE", "/user/keys/:id"},
+}
+
+// githubRequest converts a pattern to a concrete path
+// e.g. /repos/:owner/:repo -> /repos/owner/repo
+func githubRequest(pattern string) string {
+	return strings.NewReplacer(":", "", "*", "").Replace(pattern)
+}
+
+func silentLog(f func()) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	f()
+}
+
+func loadRadixRouter(routes []route) *router {
+	r := newRouter()
+	silentLog(func() {
+		for _, rt := range routes {
+			r.addRouter(rt.method, rt.path, []HandlerFunc{func(c *Context) {}})
+		}
+	})
+	return r
+}
+
+func loadLegacyRouter(routes []route) *legacyRouter {
+	r := newLegacyRouter()
+	for _, rt := range routes {
+		r.addRouter(rt.method, rt.path, func(c *Context) {})
+	}
+	return r
+}
+
+func TestRadixRouterGithubAPI(t *testing.T) {
+	r := loadRadixRouter(githubAPI)
+	for _, rt := range githubAPI {
+		n, _ := r.getRoute(rt.method, githubRequest(rt.path))
+		if n == nil {
+			t.Fatalf("%s %s: no match", rt.method, rt.path)
+		}
+		if n.pattern != rt.path {
+			t.Fatalf("%s %s: matched %s", rt.method, rt.path, n.pattern)
+		}
+	}
+}
+
+func TestRadixRouterZeroAllocation(t *testing.T) {
+	r := loadRadixRouter(githubAPI)
+	params := make(Params, 0, r.maxParams)
+	for _, p := range []string{"/user/repos", "/repos/hhgnbz/Hg-framework/issues/1/comments", "/repos/hhgnbz/Hg-framework/contents/Hg-web/hint/tree.go"} {
+		allocs := testing.AllocsPerRun(100, func() {
+			r.getValue(http.MethodGet, p, &params)
+		})
+		if allocs != 0 {
+			t.Fatalf("%s: expect zero allocations, got %v", p, allocs)
+		}
+	}
+}
+
+func benchRadix(b *testing.B, r *router, requests []route) {
+	params := make(Params, 0, r.maxParams)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, rq := range requests {
+			n := r.getValue(rq.method, rq.path, &params)
+			_ = n.handlers
+		}
+	}
+}
+
+func benchLegacy(b *testing.B, r *legacyRouter, requests []route) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, rq := range requests {
+			n, _ := r.getRoute(rq.method, rq.path)
+			_ = r.handlers[rq.method+"-"+n.pattern]
+		}
+	}
+}
+
+var (
+	githubStatic = []route{{"GET", "/user/repos"}}
+	githubParam  = []route{{"GET", "/repos/hhgnbz/Hg-framework/issues/1/comments"}}
+	githubAll    = func() []route {
+		requests := make([]route, 0, len(githubAPI))
+		for _, rt := range githubAPI {
+			requests = append(requests, route{rt.method, githubRequest(rt.path)})
+		}
+		return requests
+	}()
+)
+
+func BenchmarkRadix_GithubStatic(b *testing.B) {
+	benchRadix(b, loadRadixRouter(githubAPI), githubStatic)
+}
+
+func BenchmarkLegacy_GithubStatic(b *testing.B) {
+	benchLegacy(b, loadLegacyRouter(githubAPI), githubStatic)
+}
+
+func BenchmarkRadix_GithubParam(b *testing.B) {
+	benchRadix(b, loadRadixRouter(githubAPI), githubParam)
+}
+
+func BenchmarkLegacy_GithubParam(b *testing.B) {
+	benchLegacy(b, loadLegacyRouter(githubAPI), githubParam)
+}
+
+func BenchmarkRadix_GithubAll(b *testing.B) {
+	benchRadix(b, loadRadixRouter(githubAPI), githubAll)
+}
+
+func BenchmarkLegacy_GithubAll(b *testing.B) {
+	benchLegacy(b, loadLegacyRouter(githubAPI), githubAll)
+}
+
+// ===== legacy trie router, copied from the implementation before radix tree =====
+
+type legacyRouter struct {
+	roots    map[string]*legacyTrieNode
+	handlers map[string]HandlerFunc
+}
+
+func newLegacyRouter() *legacyRouter {
+	return &legacyRouter{
+		roots:    make(map[string]*legacyTrieNode),
+		handlers: make(map[string]HandlerFunc),
+	}
+}
+
+func (r *legacyRouter) addRouter(m string, p string, h HandlerFunc) {
+	parts := parsePattern(p)
+	if _, ok := r.roots[m]; !ok {
+		r.roots[m] = &legacyTrieNode{}
+	}
+	r.roots[m].insert(p, parts, 0)
+	r.handlers[m+"-"+p] = h
+}
+
+func (r *legacyRouter) getRoute(m string, p string) (*legacyTrieNode, map[string]string) {
+	searchParts := parsePattern(p)
+	params := make(map[string]string)
+	root, ok := r.roots[m]
+	if !ok {
+		return nil, nil
+	}
+	n := root.search(searchParts, 0)
+	if n != nil {
+		parts := parsePattern(n.pattern)
+		for index, part := range parts {
+			if part[0] == ':' {
+				params[part[1:]] = searchParts[index]
+			}
+			if part[0] == '*' && len(part) > 1 {
+				params[part[1:]] = strings.Join(searchParts[index:], "/")
+				break
+			}
+		}
+		return n, params
+	}
+	return nil, nil
+}
+
+type legacyTrieNode struct {
+	pattern    string            // current full pattern of router e.g. /p/:lang (not nil when the path fulled,"bool end" param)
+	curPattern string            // current part of full pattern e.g. /:lang
+	children   []*legacyTrieNode // child node e.g. [doc,info]
+	isWild     bool              // true when pattern contains ":" or "*"
+}
+
+// 匹配优先级: 静态 > 参数":" > 通配"*"，与注册顺序无关。
+// 同一层只允许存在一个参数节点和一个通配节点，名称不同的同层参数(例如 /:id 与 /:name)在注册时直接 panic。
+
+// get the child whose curPattern is exactly the same as curPattern, used when inserting
+func (tn *legacyTrieNode) matchChild(curPattern string) *legacyTrieNode {
+	for _, c := range tn.children {
+		if c.curPattern == curPattern {
+			return c
+		}
+	}
+	return nil
+}
+
+// get the wild child whose curPattern starts with the same wildcard as curPattern (":" or "*")
+func (tn *legacyTrieNode) wildChild(wildcard byte) *legacyTrieNode {
+	for _, c := range tn.children {
+		if c.isWild && c.curPattern[0] == wildcard {
+			return c
+		}
+	}
+	return nil
+}
+
+// get all child that the pattern matched tn.curPattern, ordered by priority
+// static first, then ":" param, then "*" catch-all
+func (tn *legacyTrieNode) matchChildren(curPattern string) []*legacyTrieNode {
+	children := make([]*legacyTrieNode, 0, 3)
+	if c := tn.matchChild(curPattern); c != nil && !c.isWild {
+		children = append(children, c)
+	}
+	if c := tn.wildChild(':'); c != nil {
+		children = append(children, c)
+	}
+	if c := tn.wildChild('*'); c != nil {
+		children = append(children, c)
+	}
+	return children
+}
+
+// insert pattern
+// panic when pattern is already registered or conflicts with an existing wildcard
+func (tn *legacyTrieNode) insert(pattern string, parts []string, depth int) {
+	// tn.pattern not nil when the path fulled
+	if depth == len(parts) {
+		if tn.pattern != "" {
+			panic(fmt.Sprintf("route '%s' conflicts with existing route '%s'", pattern, tn.pattern))
+		}
+		tn.pattern = pattern
+		return
+	}
+	curPattern := parts[depth]
+	child := tn.matchChild(curPattern)
+	// insert when child not exist
+	if child == nil {
+		isWild := curPattern[0] == ':' || curPattern[0] == '*'
+		if isWild {
+			if wc := tn.wildChild(curPattern[0]); wc != nil {
+				panic(fmt.Sprintf("wildcard '%s' in route '%s' conflicts with existing wildcard '%s'", curPattern, pattern, wc.curPattern))
+			}
+		}
+		child = &legacyTrieNode{curPattern: curPattern, isWild: isWild}
+		tn.children = append(tn.children, child)
+	}
+	child.insert(pattern, parts, depth+1)
+}
+
+// search pattern
+func (tn *legacyTrieNode) search(parts []string, depth int) *legacyTrieNode {
+	// exit when matched "*" prefix or matched fail(pattern not exist) or depth reached the end of parts(match succeed)
+	if len(parts) == depth || strings.HasPrefix(tn.curPattern, "*") {
+		if tn.pattern == "" {
+			return nil
+		}
+		return tn
+	}
+	curPattern := parts[depth]
+	children := tn.matchChildren(curPattern)
+	for _, child := range children {
+		res := child.search(parts, depth+1)
+		if res != nil {
+			return res
+		}
+	}
+	return nil
+}
+
+type nopResponseWriter struct {
+	header http.Header
+}
+
+func (w *nopResponseWriter) Header() http.Header         { return w.header }
+func (w *nopResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *nopResponseWriter) WriteHeader(int)             {}
+
+func BenchmarkEngine_GithubParam(b *testing.B) {
+	e := New()
+	silentLog(func() {
+		for _, rt := range githubAPI {
+			e.Handle(rt.method, rt.path, func(c *Context) {})
+		}
+	})
+	req := httptest.NewRequest(http.MethodGet, githubParam[0].path, nil)
+	w := &nopResponseWriter{header: make(http.Header)}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e.ServeHTTP(w, req)
+	}
+}
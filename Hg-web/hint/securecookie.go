@@ -0,0 +1,188 @@
+package hint
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// cookie 保存在客户端，可以被任意修改，因此需要签名(HMAC-SHA256)防止篡改，需要保密时再用 AES-GCM 加密。
+// 编码格式: base64url(时间戳(8字节) | 内容 | HMAC(name | 时间戳 | 内容))，加密时内容为 nonce | 密文。
+// cookie 名参与签名，一个 cookie 的值不能被挪用为另一个 cookie。
+// 密钥轮换: 传入多组密钥，编码总是使用第一组，解码依次尝试每一组，e.g.
+//
+//	codecs := hint.NewCookieCodecs(newHashKey, newBlockKey, oldHashKey, oldBlockKey)
+
+var (
+	ErrCookieInvalid = errors.New("securecookie: the value is not valid")
+	ErrCookieExpired = errors.New("securecookie: expired timestamp")
+	ErrCookieTooLong = errors.New("securecookie: the value is too long")
+)
+
+// maxCookieLength is the max length of the encoded value, browsers limit a cookie to 4096 bytes
+const maxCookieLength = 4096
+
+// CookieOptions stores the attributes of cookie, see http.Cookie
+type CookieOptions struct {
+	Path   string
+	Domain string
+	// MaxAge=0 means no Max-Age attribute (a session cookie), <0 means deleting the cookie now, >0 means seconds
+	MaxAge   int
+	Secure   bool
+	HttpOnly bool
+	SameSite http.SameSite
+}
+
+// newCookie creates a http.Cookie with the options
+func (o *CookieOptions) newCookie(name, value string) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     o.Path,
+		Domain:   o.Domain,
+		MaxAge:   o.MaxAge,
+		Secure:   o.Secure,
+		HttpOnly: o.HttpOnly,
+		SameSite: o.SameSite,
+	}
+	if o.MaxAge > 0 {
+		cookie.Expires = time.Now().Add(time.Duration(o.MaxAge) * time.Second)
+	} else if o.MaxAge < 0 {
+		cookie.Expires = time.Unix(1, 0)
+	}
+	return cookie
+}
+
+// CookieCodec signs and optionally encrypts the value of cookie
+type CookieCodec struct {
+	hashKey []byte
+	aead    cipher.AEAD // nil when the value is only signed
+}
+
+// NewCookieCodec creates a CookieCodec, hashKey is used for HMAC and should be at least 32 bytes
+// blockKey enables encryption with AES-GCM, it must be 16, 24 or 32 bytes for AES-128, AES-192 or AES-256
+func NewCookieCodec(hashKey, blockKey []byte) *CookieCodec {
+	if len(hashKey) == 0 {
+		panic("securecookie: hash key is required")
+	}
+	codec := &CookieCodec{hashKey: hashKey}
+	if blockKey != nil {
+		block, err := aes.NewCipher(blockKey)
+		if err != nil {
+			panic("securecookie: " + err.Error())
+		}
+		if codec.aead, err = cipher.NewGCM(block); err != nil {
+			panic("securecookie: " + err.Error())
+		}
+	}
+	return codec
+}
+
+// NewCookieCodecs creates codecs from pairs of hash key and block key, the block key of the last pair can be omitted
+func NewCookieCodecs(keyPairs ...[]byte) []*CookieCodec {
+	codecs := make([]*CookieCodec, 0, (len(keyPairs)+1)/2)
+	for i := 0; i < len(keyPairs); i += 2 {
+		var blockKey []byte
+		if i+1 < len(keyPairs) {
+			blockKey = keyPairs[i+1]
+		}
+		codecs = append(codecs, NewCookieCodec(keyPairs[i], blockKey))
+	}
+	return codecs
+}
+
+func (s *CookieCodec) mac(name string, data []byte) []byte {
+	h := hmac.New(sha256.New, s.hashKey)
+	h.Write([]byte(name))
+	h.Write([]byte{'|'})
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// Encode signs (and encrypts) the value of the cookie named name
+func (s *CookieCodec) Encode(name string, value []byte) (string, error) {
+	data := make([]byte, 8, 8+len(value)+64)
+	binary.BigEndian.PutUint64(data, uint64(time.Now().Unix()))
+	if s.aead != nil {
+		nonce := make([]byte, s.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return "", err
+		}
+		data = append(data, nonce...)
+		data = s.aead.Seal(data, nonce, value, []byte(name))
+	} else {
+		data = append(data, value...)
+	}
+	data = append(data, s.mac(name, data)...)
+	encoded := base64.RawURLEncoding.EncodeToString(data)
+	if len(encoded) > maxCookieLength {
+		return "", ErrCookieTooLong
+	}
+	return encoded, nil
+}
+
+// Decode verifies (and decrypts) the value of the cookie named name
+// the value is regarded as expired when it's older than maxAge, 0 means no limit
+func (s *CookieCodec) Decode(name string, value string, maxAge time.Duration) ([]byte, error) {
+	if len(value) > maxCookieLength {
+		return nil, ErrCookieTooLong
+	}
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(data) < 8+sha256.Size {
+		return nil, ErrCookieInvalid
+	}
+	data, sum := data[:len(data)-sha256.Size], data[len(data)-sha256.Size:]
+	if !hmac.Equal(sum, s.mac(name, data)) {
+		return nil, ErrCookieInvalid
+	}
+	ts := time.Unix(int64(binary.BigEndian.Uint64(data[:8])), 0)
+	if maxAge > 0 && time.Since(ts) > maxAge {
+		return nil, ErrCookieExpired
+	}
+	data = data[8:]
+	if s.aead == nil {
+		return data, nil
+	}
+	if len(data) < s.aead.NonceSize() {
+		return nil, ErrCookieInvalid
+	}
+	nonce, ciphertext := data[:s.aead.NonceSize()], data[s.aead.NonceSize():]
+	plain, err := s.aead.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return nil, ErrCookieInvalid
+	}
+	return plain, nil
+}
+
+// encodeCookie encodes with the first codec
+func encodeCookie(codecs []*CookieCodec, name string, value []byte) (string, error) {
+	if len(codecs) == 0 {
+		return "", errors.New("securecookie: no codecs")
+	}
+	return codecs[0].Encode(name, value)
+}
+
+// decodeCookie tries the codecs in order, the error of the first codec is returned when all of them fail
+func decodeCookie(codecs []*CookieCodec, name string, value string, maxAge time.Duration) ([]byte, error) {
+	var firstErr error
+	for _, codec := range codecs {
+		plain, err := codec.Decode(name, value, maxAge)
+		if err == nil {
+			return plain, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr == nil {
+		firstErr = errors.New("securecookie: no codecs")
+	}
+	return nil, firstErr
+}
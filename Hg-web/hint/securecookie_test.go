@@ -0,0 +1,41 @@
+package hint
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCookieCodec(t *testing.T) {
+	hashKey := []byte("0123456789abcdef0123456789abcdef")
+	blockKey := []byte("0123456789abcdef")
+	for _, codec := range []*CookieCodec{NewCookieCodec(hashKey, nil), NewCookieCodec(hashKey, blockKey)} {
+		encoded, err := codec.Encode("sid", []byte("hint"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if plain, err := codec.Decode("sid", encoded, 0); err != nil || string(plain) != "hint" {
+			t.Fatalf("unexpected decoded value %q %v", plain, err)
+		}
+		// the value can't be moved to another cookie
+		if _, err = codec.Decode("other", encoded, 0); !errors.Is(err, ErrCookieInvalid) {
+			t.Fatalf("expect ErrCookieInvalid, got %v", err)
+		}
+		tampered := []byte(encoded)
+		tampered[12] ^= 1
+		if _, err = codec.Decode("sid", string(tampered), 0); !errors.Is(err, ErrCookieInvalid) {
+			t.Fatalf("expect ErrCookieInvalid of tampered value, got %v", err)
+		}
+	}
+
+	// key rotation
+	oldCodecs := NewCookieCodecs([]byte("old hash key"), blockKey)
+	codecs := NewCookieCodecs(hashKey, blockKey, []byte("old hash key"), blockKey)
+	encoded, _ := encodeCookie(oldCodecs, "sid", []byte("hint"))
+	if plain, err := decodeCookie(codecs, "sid", encoded, 0); err != nil || string(plain) != "hint" {
+		t.Fatalf("value of old key is not decoded: %q %v", plain, err)
+	}
+	encoded, _ = encodeCookie(codecs, "sid", []byte("hint"))
+	if _, err := decodeCookie(oldCodecs, "sid", encoded, 0); err == nil {
+		t.Fatal("new value should be encoded with the first key")
+	}
+}
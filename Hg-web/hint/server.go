@@ -0,0 +1,190 @@
+package hint
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+)
+
+// 直接调用 http.ListenAndServe 时，进程退出会中断正在处理的请求。
+// 这里为每次启动创建 http.Server 并记录下来，Shutdown 时先停止接收新连接，再等待正在处理的请求完成(连接排空)。
+// 生命周期钩子用于把其他组件与 web 服务一起启动/停止，例如:
+//
+//	e.OnStart(func() error {
+//		go registry.Heartbeat(registryAddr, "tcp@"+addr, 0)
+//		return nil
+//	})
+//	e.OnShutdown(func(ctx context.Context) error {
+//		return db.Close()
+//	})
+
+// OnStart registers a hook which is called before the first server starts serving
+// the server is not started when any hook returns an error
+func (e *Engine) OnStart(hook func() error) {
+	e.srvMu.Lock()
+	defer e.srvMu.Unlock()
+	e.onStart = append(e.onStart, hook)
+}
+
+// OnShutdown registers a hook which is called by Shutdown after all connections are drained
+// hooks are called in reverse order of registration
+func (e *Engine) OnShutdown(hook func(ctx context.Context) error) {
+	e.srvMu.Lock()
+	defer e.srvMu.Unlock()
+	e.onShutdown = append(e.onShutdown, hook)
+}
+
+// Run is a method for users to run the server on appoint port
+// it returns nil as soon as Shutdown is called, wait for Shutdown to return to make sure requests are drained
+func (e *Engine) Run(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return e.RunListener(ln)
+}
+
+// RunTLS is like Run but serves HTTPS with the certificate and the matching private key
+func (e *Engine) RunTLS(addr string, certFile string, keyFile string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return e.serve(ln, func(srv *http.Server) error {
+		return srv.ServeTLS(ln, certFile, keyFile)
+	})
+}
+
+// RunUnix is like Run but listens on the unix domain socket file
+// the file is removed before listening and after the server is closed
+func (e *Engine) RunUnix(file string) error {
+	if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	ln, err := net.Listen("unix", file)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file)
+	return e.RunListener(ln)
+}
+
+// RunListener serves HTTP requests on the listener, the listener is closed when the server is closed
+func (e *Engine) RunListener(ln net.Listener) error {
+	return e.serve(ln, func(srv *http.Server) error {
+		return srv.Serve(ln)
+	})
+}
+
+// RunContext is like Run but shuts the server down gracefully when ctx is done
+// it returns after all connections are drained or ShutdownTimeout is reached
+func (e *Engine) RunContext(ctx context.Context, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- e.RunListener(ln)
+	}()
+	select {
+	case err = <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx := context.Background()
+	if e.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		shutdownCtx, cancel = context.WithTimeout(shutdownCtx, e.ShutdownTimeout)
+		defer cancel()
+	}
+	if err = e.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	return <-errCh
+}
+
+// Shutdown stops all running servers gracefully, it stops accepting new connections
+// waits for the in-flight requests to finish, and then calls OnShutdown hooks
+// connections are closed forcibly and ctx.Err() is returned when ctx is done before draining
+func (e *Engine) Shutdown(ctx context.Context) error {
+	e.srvMu.Lock()
+	servers := make([]*http.Server, 0, len(e.servers))
+	for srv := range e.servers {
+		servers = append(servers, srv)
+	}
+	hooks := e.onShutdown
+	e.closed = true
+	e.srvMu.Unlock()
+
+	var errs []error
+	for _, srv := range servers {
+		if err := srv.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+			_ = srv.Close()
+		}
+	}
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i](ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// newServer creates a http.Server with the timeouts of Engine
+func (e *Engine) newServer() *http.Server {
+	return &http.Server{
+		Handler:           e,
+		ReadTimeout:       e.ReadTimeout,
+		ReadHeaderTimeout: e.ReadHeaderTimeout,
+		WriteTimeout:      e.WriteTimeout,
+		IdleTimeout:       e.IdleTimeout,
+	}
+}
+
+// serve runs OnStart hooks, tracks the server until it's closed and calls serveFunc
+// an Engine can't be served again after Shutdown, just like http.Server
+func (e *Engine) serve(ln net.Listener, serveFunc func(srv *http.Server) error) error {
+	e.srvMu.Lock()
+	var hooks []func() error
+	if !e.started {
+		hooks, e.started = e.onStart, true
+	}
+	e.srvMu.Unlock()
+	// hooks run without holding the lock, so that they can register OnShutdown hooks
+	for _, hook := range hooks {
+		if err := hook(); err != nil {
+			_ = ln.Close()
+			return err
+		}
+	}
+
+	srv := e.newServer()
+	e.srvMu.Lock()
+	if e.closed {
+		e.srvMu.Unlock()
+		_ = ln.Close()
+		return nil
+	}
+	if e.servers == nil {
+		e.servers = make(map[*http.Server]struct{})
+	}
+	e.servers[srv] = struct{}{}
+	e.srvMu.Unlock()
+
+	defer func() {
+		e.srvMu.Lock()
+		delete(e.servers, srv)
+		e.srvMu.Unlock()
+	}()
+
+	err := serveFunc(srv)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,145 @@
+package hint
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShutdownDrainsConnections(t *testing.T) {
+	e := New()
+	started := make(chan struct{})
+	e.GET("/slow", func(c *Context) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		c.String(http.StatusOK, "done")
+	})
+	hooks := make([]string, 0)
+	e.OnStart(func() error {
+		hooks = append(hooks, "start")
+		return nil
+	})
+	e.OnShutdown(func(ctx context.Context) error {
+		hooks = append(hooks, "shutdown1")
+		return nil
+	})
+	e.OnShutdown(func(ctx context.Context) error {
+		hooks = append(hooks, "shutdown2")
+		return nil
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- e.RunListener(ln)
+	}()
+
+	respCh := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String() + "/slow")
+		if err != nil {
+			respCh <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		respCh <- string(body)
+	}()
+
+	<-started
+	if err := e.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// in-flight request is finished before Shutdown returns
+	select {
+	case body := <-respCh:
+		if body != "done" {
+			t.Fatalf("in-flight request is dropped: %s", body)
+		}
+	default:
+		t.Fatal("Shutdown returned before the in-flight request finished")
+	}
+	if err := <-runErr; err != nil {
+		t.Fatalf("RunListener should return nil after Shutdown, got %v", err)
+	}
+	if strings.Join(hooks, ",") != "start,shutdown2,shutdown1" {
+		t.Fatalf("unexpected hooks %v", hooks)
+	}
+}
+
+func TestRunContext(t *testing.T) {
+	e := New()
+	e.ReadHeaderTimeout = time.Second
+	e.ShutdownTimeout = time.Second
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- e.RunContext(ctx, "127.0.0.1:0")
+	}()
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("RunContext should return after ctx is cancelled")
+	}
+}
+
+func TestOnStartError(t *testing.T) {
+	e := New()
+	e.OnStart(func() error {
+		return errors.New("registry is unavailable")
+	})
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.RunListener(ln); err == nil || err.Error() != "registry is unavailable" {
+		t.Fatalf("expect the error of OnStart hook, got %v", err)
+	}
+}
+
+func TestRunUnix(t *testing.T) {
+	e := New()
+	e.GET("/ping", func(c *Context) {
+		c.String(http.StatusOK, "pong")
+	})
+	file := filepath.Join(t.TempDir(), "hint.sock")
+	go func() {
+		_ = e.RunUnix(file)
+	}()
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", file)
+		},
+	}}
+	var resp *http.Response
+	var err error
+	for i := 0; i < 50; i++ {
+		if resp, err = client.Get("http://unix/ping"); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if body, _ := io.ReadAll(resp.Body); string(body) != "pong" {
+		t.Fatalf("unexpected body %q", body)
+	}
+	if err := e.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,383 @@
+package hint
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/gob"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// 会话(session)，用于在多个请求之间保存用户状态，例如登录用户。
+// CookieStore 把会话数据签名加密后整个保存在 cookie 中，服务端无状态，适合数据较少的场景(cookie 最大 4KB)。
+// MemoryStore 只在 cookie 中保存签名后的会话 ID，数据保存在进程内存中并按 MaxAge 过期。
+// 会话数据用 encoding/gob 序列化，自定义类型需要先 gob.Register。
+// 修改会话后需要在写响应之前调用 Save，e.g.
+//
+//	e.Use(hint.Sessions("hint_session", hint.NewCookieStore(hashKey, blockKey)))
+//	e.POST("/login", func(c *hint.Context) {
+//		s := c.Session()
+//		s.Regenerate() // 登录后更换会话，防止会话固定攻击
+//		s.Set("user", "hg")
+//		s.AddFlash("welcome back")
+//		if err := s.Save(); err != nil { ... }
+//		c.String(http.StatusOK, "ok")
+//	})
+
+const (
+	sessionKey = "_hint/session"
+	flashKey   = "_flash"
+	// defaultSessionMaxAge is 30 days
+	defaultSessionMaxAge = 86400 * 30
+)
+
+var errSessionWritten = errors.New("session: the response header has been written")
+
+func init() {
+	gob.Register([]interface{}{})
+}
+
+// SessionStore loads and saves sessions, it must be safe for concurrent use
+type SessionStore interface {
+	// Get returns the session named name of the request
+	// a new session is returned with the error when the session is absent or invalid
+	Get(c *Context, name string) (*Session, error)
+	// Save writes the session to the response
+	Save(c *Context, session *Session) error
+}
+
+// Session stores the values of a user between requests
+type Session struct {
+	// ID is the id of session in the server-side store, "" for CookieStore and new sessions
+	ID     string
+	Values map[string]interface{}
+	// Options is copied from the store, it can be modified for a session, e.g. MaxAge=-1 to delete it
+	Options CookieOptions
+	IsNew   bool
+
+	name  string
+	store SessionStore
+	c     *Context
+	// oldID is the id to be removed from the store after Regenerate
+	oldID string
+}
+
+// NewSession creates a new session, it's used by SessionStore implementations
+func NewSession(c *Context, store SessionStore, name string, options CookieOptions) *Session {
+	return &Session{
+		Values:  make(map[string]interface{}),
+		Options: options,
+		IsNew:   true,
+		name:    name,
+		store:   store,
+		c:       c,
+	}
+}
+
+// Name returns the name of session, which is the name of cookie as well
+func (s *Session) Name() string {
+	return s.name
+}
+
+// Get returns the value of key, nil when it's absent
+func (s *Session) Get(key string) interface{} {
+	return s.Values[key]
+}
+
+// Set sets the value of key
+func (s *Session) Set(key string, value interface{}) {
+	s.Values[key] = value
+}
+
+// Delete removes the value of key
+func (s *Session) Delete(key string) {
+	delete(s.Values, key)
+}
+
+// Clear removes all the values
+func (s *Session) Clear() {
+	for key := range s.Values {
+		delete(s.Values, key)
+	}
+}
+
+// AddFlash adds a flash message, which is removed after it's read by Flashes
+func (s *Session) AddFlash(value interface{}) {
+	flashes, _ := s.Values[flashKey].([]interface{})
+	s.Values[flashKey] = append(flashes, value)
+}
+
+// Flashes returns and removes the flash messages, call Save to persist the removal
+func (s *Session) Flashes() []interface{} {
+	flashes, _ := s.Values[flashKey].([]interface{})
+	delete(s.Values, flashKey)
+	return flashes
+}
+
+// Regenerate issues a new session id for the values when saving, the old session is removed from the store
+// call it after login to prevent session fixation
+func (s *Session) Regenerate() {
+	if s.ID != "" {
+		s.oldID = s.ID
+	}
+	s.ID = ""
+	s.IsNew = true
+}
+
+// Destroy clears the values and deletes the session from the store and the client when saving
+func (s *Session) Destroy() {
+	s.Clear()
+	s.Options.MaxAge = -1
+}
+
+// Save writes the session to the response, it must be called before writing the response body
+func (s *Session) Save() error {
+	if s.c.Writer.Written() {
+		return errSessionWritten
+	}
+	return s.store.Save(s.c, s)
+}
+
+// Sessions returns a middleware which provides the session named name to Context.Session
+// the session is loaded lazily when Context.Session is called for the first time
+func Sessions(name string, store SessionStore) HandlerFunc {
+	return func(c *Context) {
+		c.Set(sessionKey, &lazySession{name: name, store: store})
+		c.Next()
+	}
+}
+
+type lazySession struct {
+	name    string
+	store   SessionStore
+	session *Session
+}
+
+// bindContext implements contextBound, the loaded session is copied so that it's saved to the response of c
+func (l *lazySession) bindContext(c *Context) interface{} {
+	cp := &lazySession{name: l.name, store: l.store}
+	if l.session != nil {
+		s := *l.session
+		s.Values = make(map[string]interface{}, len(l.session.Values))
+		for k, v := range l.session.Values {
+			s.Values[k] = v
+		}
+		s.c = c
+		cp.session = &s
+	}
+	return cp
+}
+
+// Session returns the session provided by the Sessions middleware, it panics when the middleware is not used
+// a new session is returned when the session of the request is invalid, the error is attached to Context
+func (c *Context) Session() *Session {
+	v, ok := c.Get(sessionKey)
+	if !ok {
+		panic("hint: Sessions middleware is not used")
+	}
+	lazy := v.(*lazySession)
+	if lazy.session == nil {
+		s, err := lazy.store.Get(c, lazy.name)
+		if err != nil {
+			_ = c.Error(err)
+		}
+		lazy.session = s
+	}
+	return lazy.session
+}
+
+// encodeSessionValues serializes the values with encoding/gob
+func encodeSessionValues(values map[string]interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(values); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeSessionValues(data []byte) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func defaultSessionOptions() CookieOptions {
+	return CookieOptions{Path: "/", MaxAge: defaultSessionMaxAge, HttpOnly: true, SameSite: http.SameSiteLaxMode}
+}
+
+// CookieStore stores the sessions in signed and encrypted cookies
+type CookieStore struct {
+	Codecs []*CookieCodec
+	// Options are the default cookie attributes of the sessions, Path=/, MaxAge=30 days, HttpOnly and SameSite=Lax by default
+	Options CookieOptions
+}
+
+var _ SessionStore = (*CookieStore)(nil)
+
+// NewCookieStore creates a CookieStore with pairs of hash key and block key, see NewCookieCodecs
+func NewCookieStore(keyPairs ...[]byte) *CookieStore {
+	return &CookieStore{Codecs: NewCookieCodecs(keyPairs...), Options: defaultSessionOptions()}
+}
+
+// Get implements SessionStore
+func (s *CookieStore) Get(c *Context, name string) (*Session, error) {
+	session := NewSession(c, s, name, s.Options)
+	cookie, err := c.Req.Cookie(name)
+	if err != nil {
+		return session, nil
+	}
+	data, err := decodeCookie(s.Codecs, name, cookie.Value, maxAgeDuration(s.Options.MaxAge))
+	if err != nil {
+		return session, err
+	}
+	values, err := decodeSessionValues(data)
+	if err != nil {
+		return session, err
+	}
+	session.Values = values
+	session.IsNew = false
+	return session, nil
+}
+
+// Save implements SessionStore
+func (s *CookieStore) Save(c *Context, session *Session) error {
+	if session.Options.MaxAge < 0 {
+		http.SetCookie(c.Writer, session.Options.newCookie(session.name, ""))
+		return nil
+	}
+	data, err := encodeSessionValues(session.Values)
+	if err != nil {
+		return err
+	}
+	encoded, err := encodeCookie(s.Codecs, session.name, data)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(c.Writer, session.Options.newCookie(session.name, encoded))
+	return nil
+}
+
+// MemoryStore stores the sessions in process memory, the cookie only carries the signed session id
+// sessions expire after MaxAge of Options, or 30 days for session cookies (MaxAge=0)
+type MemoryStore struct {
+	Codecs  []*CookieCodec
+	Options CookieOptions
+
+	mu        sync.Mutex
+	sessions  map[string]memorySession
+	nextSweep time.Time
+}
+
+type memorySession struct {
+	data   []byte
+	expire time.Time
+}
+
+var _ SessionStore = (*MemoryStore)(nil)
+
+// NewMemoryStore creates a MemoryStore, keyPairs sign the session id, see NewCookieCodecs
+func NewMemoryStore(keyPairs ...[]byte) *MemoryStore {
+	return &MemoryStore{
+		Codecs:   NewCookieCodecs(keyPairs...),
+		Options:  defaultSessionOptions(),
+		sessions: make(map[string]memorySession),
+	}
+}
+
+// Get implements SessionStore
+func (s *MemoryStore) Get(c *Context, name string) (*Session, error) {
+	session := NewSession(c, s, name, s.Options)
+	cookie, err := c.Req.Cookie(name)
+	if err != nil {
+		return session, nil
+	}
+	id, err := decodeCookie(s.Codecs, name, cookie.Value, maxAgeDuration(s.Options.MaxAge))
+	if err != nil {
+		return session, err
+	}
+	s.mu.Lock()
+	stored, ok := s.sessions[string(id)]
+	s.mu.Unlock()
+	if !ok || time.Now().After(stored.expire) {
+		// expired or removed, a new session is returned silently
+		return session, nil
+	}
+	values, err := decodeSessionValues(stored.data)
+	if err != nil {
+		return session, err
+	}
+	session.ID = string(id)
+	session.Values = values
+	session.IsNew = false
+	return session, nil
+}
+
+// Save implements SessionStore
+func (s *MemoryStore) Save(c *Context, session *Session) error {
+	if session.Options.MaxAge < 0 {
+		s.mu.Lock()
+		delete(s.sessions, session.ID)
+		delete(s.sessions, session.oldID)
+		s.mu.Unlock()
+		http.SetCookie(c.Writer, session.Options.newCookie(session.name, ""))
+		return nil
+	}
+	data, err := encodeSessionValues(session.Values)
+	if err != nil {
+		return err
+	}
+	if session.ID == "" {
+		if session.ID, err = newSessionID(); err != nil {
+			return err
+		}
+	}
+	encoded, err := encodeCookie(s.Codecs, session.name, []byte(session.ID))
+	if err != nil {
+		return err
+	}
+	maxAge := session.Options.MaxAge
+	if maxAge == 0 {
+		maxAge = defaultSessionMaxAge
+	}
+	now := time.Now()
+
+	s.mu.Lock()
+	if now.After(s.nextSweep) {
+		for id, stored := range s.sessions {
+			if now.After(stored.expire) {
+				delete(s.sessions, id)
+			}
+		}
+		s.nextSweep = now.Add(time.Minute)
+	}
+	if session.oldID != "" {
+		delete(s.sessions, session.oldID)
+		session.oldID = ""
+	}
+	s.sessions[session.ID] = memorySession{data: data, expire: now.Add(time.Duration(maxAge) * time.Second)}
+	s.mu.Unlock()
+
+	http.SetCookie(c.Writer, session.Options.newCookie(session.name, encoded))
+	return nil
+}
+
+// newSessionID returns a random id of 32 bytes
+func newSessionID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func maxAgeDuration(maxAge int) time.Duration {
+	if maxAge <= 0 {
+		return 0
+	}
+	return time.Duration(maxAge) * time.Second
+}
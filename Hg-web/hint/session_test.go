@@ -0,0 +1,108 @@
+package hint
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// sessionClient keeps cookies between requests like a browser
+type sessionClient struct {
+	e       *Engine
+	cookies map[string]*http.Cookie
+}
+
+func (client *sessionClient) do(method, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for _, cookie := range client.cookies {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	client.e.ServeHTTP(w, req)
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.MaxAge < 0 {
+			delete(client.cookies, cookie.Name)
+		} else {
+			client.cookies[cookie.Name] = cookie
+		}
+	}
+	return w
+}
+
+func newSessionEngine(store SessionStore) *Engine {
+	e := New()
+	e.Use(Sessions("hint_session", store))
+	e.POST("/login", func(c *Context) {
+		s := c.Session()
+		s.Regenerate()
+		s.Set("user", "hg")
+		s.AddFlash("welcome")
+		if err := s.Save(); err != nil {
+			c.Fail(http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.String(http.StatusOK, "ok")
+	})
+	e.GET("/me", func(c *Context) {
+		s := c.Session()
+		flashes := s.Flashes()
+		_ = s.Save()
+		c.String(http.StatusOK, "%v %v", s.Get("user"), flashes)
+	})
+	e.POST("/logout", func(c *Context) {
+		s := c.Session()
+		s.Destroy()
+		_ = s.Save()
+	})
+	return e
+}
+
+func TestSessionStores(t *testing.T) {
+	hashKey := []byte("0123456789abcdef0123456789abcdef")
+	blockKey := []byte("0123456789abcdef")
+	stores := map[string]SessionStore{
+		"cookie": NewCookieStore(hashKey, blockKey),
+		"memory": NewMemoryStore(hashKey),
+	}
+	for name, store := range stores {
+		client := &sessionClient{e: newSessionEngine(store), cookies: map[string]*http.Cookie{}}
+		if w := client.do(http.MethodGet, "/me"); w.Body.String() != "<nil> []" {
+			t.Fatalf("%s: unexpected anonymous session %q", name, w.Body.String())
+		}
+		client.do(http.MethodPost, "/login")
+		cookie := client.cookies["hint_session"]
+		if cookie == nil || !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode || cookie.Path != "/" {
+			t.Fatalf("%s: unexpected cookie %v", name, cookie)
+		}
+		if w := client.do(http.MethodGet, "/me"); w.Body.String() != "hg [welcome]" {
+			t.Fatalf("%s: unexpected session %q", name, w.Body.String())
+		}
+		// flashes are removed after reading
+		if w := client.do(http.MethodGet, "/me"); w.Body.String() != "hg []" {
+			t.Fatalf("%s: flashes are not removed, got %q", name, w.Body.String())
+		}
+		client.do(http.MethodPost, "/logout")
+		if w := client.do(http.MethodGet, "/me"); w.Body.String() != "<nil> []" {
+			t.Fatalf("%s: session is not destroyed, got %q", name, w.Body.String())
+		}
+	}
+}
+
+func TestMemoryStoreRegenerate(t *testing.T) {
+	store := NewMemoryStore([]byte("0123456789abcdef0123456789abcdef"))
+	client := &sessionClient{e: newSessionEngine(store), cookies: map[string]*http.Cookie{}}
+	client.do(http.MethodPost, "/login")
+	fixed := client.cookies["hint_session"]
+	client.do(http.MethodPost, "/login")
+	if client.cookies["hint_session"].Value == fixed.Value {
+		t.Fatal("session id is not regenerated after login")
+	}
+	if len(store.sessions) != 1 {
+		t.Fatalf("old session is not removed, %d sessions", len(store.sessions))
+	}
+	// the old session id is no longer valid
+	client.cookies["hint_session"] = fixed
+	if w := client.do(http.MethodGet, "/me"); w.Body.String() != "<nil> []" {
+		t.Fatalf("old session is still valid, got %q", w.Body.String())
+	}
+}
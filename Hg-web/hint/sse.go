@@ -0,0 +1,169 @@
+package hint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Server-Sent Events(https://html.spec.whatwg.org/multipage/server-sent-events.html) 与流式响应。
+// 每个事件由若干行 "field: value" 组成，以空行结束，e.g.
+//
+//	id: 42
+//	event: cpu
+//	retry: 3000
+//	data: {"usage":0.5}
+//
+// 以 ":" 开头的行是注释，用作心跳，避免连接被代理因空闲而关闭。
+// 浏览器断线重连时会在 Last-Event-ID 头中带上最后收到的事件 id，通过 c.LastEventID() 获取后从该位置继续推送，e.g.
+//
+//	e.GET("/events", func(c *hint.Context) {
+//		events := dashboard.Subscribe(c.LastEventID())
+//		defer dashboard.Unsubscribe(events)
+//		c.SSEStream(15*time.Second, events)
+//	})
+
+var sseContentType = []string{"text/event-stream"}
+
+// SSEvent is an event of Server-Sent Events, it implements Render
+type SSEvent struct {
+	ID    string
+	Event string
+	// Retry tells the client the reconnection time, 0 means not set
+	Retry time.Duration
+	// Data is written as is when it's a string or []byte, otherwise as JSON
+	Data interface{}
+}
+
+// Render implements Render, data containing line breaks is split into multiple data lines
+func (e SSEvent) Render(w http.ResponseWriter) error {
+	e.WriteContentType(w)
+	var sb strings.Builder
+	if e.ID != "" {
+		sb.WriteString("id: " + sseField(e.ID) + "\n")
+	}
+	if e.Event != "" {
+		sb.WriteString("event: " + sseField(e.Event) + "\n")
+	}
+	if e.Retry > 0 {
+		sb.WriteString("retry: " + strconv.FormatInt(e.Retry.Milliseconds(), 10) + "\n")
+	}
+	var data string
+	switch d := e.Data.(type) {
+	case nil:
+	case string:
+		data = d
+	case []byte:
+		data = string(d)
+	default:
+		b, err := json.Marshal(d)
+		if err != nil {
+			return err
+		}
+		data = string(b)
+	}
+	if e.Data != nil {
+		data = strings.ReplaceAll(strings.ReplaceAll(data, "\r\n", "\n"), "\r", "\n")
+		for _, line := range strings.Split(data, "\n") {
+			sb.WriteString("data: " + line + "\n")
+		}
+	}
+	sb.WriteByte('\n')
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteContentType implements Render, the response must not be cached or buffered by proxies
+func (e SSEvent) WriteContentType(w http.ResponseWriter) {
+	header := w.Header()
+	header["Content-Type"] = sseContentType
+	if header.Get("Cache-Control") == "" {
+		header.Set("Cache-Control", "no-cache")
+	}
+	header.Set("X-Accel-Buffering", "no")
+}
+
+// sseField removes the line breaks, which terminate a field
+func sseField(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
+
+// SSEvent writes a Server-Sent Event and flushes it to the client
+// e.g. c.SSEvent("message", H{"text": "hello"})
+func (c *Context) SSEvent(name string, data interface{}) {
+	c.SendSSEvent(SSEvent{Event: name, Data: data})
+}
+
+// SendSSEvent is like SSEvent but with the id and retry of event
+func (c *Context) SendSSEvent(event SSEvent) {
+	c.Render(-1, event)
+	c.Writer.Flush()
+}
+
+// SSEComment writes a comment line, which is ignored by the client, e.g. a heartbeat
+func (c *Context) SSEComment(text string) {
+	SSEvent{}.WriteContentType(c.Writer)
+	_, _ = c.Writer.WriteString(": " + sseField(text) + "\n\n")
+	c.Writer.Flush()
+}
+
+// LastEventID returns the id of the last event received by the client before reconnecting
+// the query param lastEventId is used when the header is absent, as some polyfills can't set headers
+func (c *Context) LastEventID() string {
+	if id := c.Req.Header.Get("Last-Event-ID"); id != "" {
+		return id
+	}
+	return c.Query("lastEventId")
+}
+
+// Stream calls step repeatedly and flushes the response after each call, until step returns false
+// or the client disconnects, it returns true when the client disconnects
+func (c *Context) Stream(step func(w io.Writer) bool) bool {
+	done := c.Req.Context().Done()
+	for {
+		select {
+		case <-done:
+			return true
+		default:
+			keepOpen := step(c.Writer)
+			c.Writer.Flush()
+			if !keepOpen {
+				return false
+			}
+		}
+	}
+}
+
+// SSEStream sends the events from ch until ch is closed or the client disconnects
+// a heartbeat comment is sent every heartbeat, 0 means no heartbeat
+// it returns true when the client disconnects
+func (c *Context) SSEStream(heartbeat time.Duration, ch <-chan SSEvent) bool {
+	done := c.Req.Context().Done()
+	var tick <-chan time.Time
+	if heartbeat > 0 {
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	// send the header at once, so that the client knows the stream is open
+	SSEvent{}.WriteContentType(c.Writer)
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+	for {
+		select {
+		case <-done:
+			return true
+		case event, ok := <-ch:
+			if !ok {
+				return false
+			}
+			c.SendSSEvent(event)
+		case t := <-tick:
+			c.SSEComment(fmt.Sprintf("heartbeat %d", t.Unix()))
+		}
+	}
+}
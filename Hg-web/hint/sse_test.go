@@ -0,0 +1,83 @@
+package hint
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSSEvent(t *testing.T) {
+	e := New()
+	e.GET("/events", func(c *Context) {
+		c.SendSSEvent(SSEvent{ID: "1", Event: "cpu", Retry: 3 * time.Second, Data: H{"usage": 0.5}})
+		c.SSEvent("message", "line1\nline2")
+		c.SSEComment("ping")
+		c.String(http.StatusOK, "last=%s", c.LastEventID())
+	})
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	req.Header.Set("Last-Event-ID", "0")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+
+	want := "id: 1\nevent: cpu\nretry: 3000\ndata: {\"usage\":0.5}\n\n" +
+		"event: message\ndata: line1\ndata: line2\n\n" +
+		": ping\n\n" +
+		"last=0"
+	if w.Body.String() != want {
+		t.Fatalf("unexpected events %q", w.Body.String())
+	}
+	header := w.Result().Header
+	if header.Get("Content-Type") != "text/event-stream" || header.Get("Cache-Control") != "no-cache" || !w.Flushed {
+		t.Fatalf("unexpected response %v flushed=%v", header, w.Flushed)
+	}
+}
+
+func TestStream(t *testing.T) {
+	e := New()
+	e.GET("/stream", func(c *Context) {
+		i := 0
+		gone := c.Stream(func(w io.Writer) bool {
+			i++
+			fmt.Fprintf(w, "%d,", i)
+			return i < 3
+		})
+		fmt.Fprintf(c.Writer, "%v", gone)
+	})
+	if w := performRequest(e, http.MethodGet, "/stream"); w.Body.String() != "1,2,3,false" {
+		t.Fatalf("unexpected stream %q", w.Body.String())
+	}
+}
+
+func TestSSEStream(t *testing.T) {
+	ch := make(chan SSEvent)
+	gone := make(chan bool, 1)
+	e := New()
+	e.GET("/events", func(c *Context) {
+		gone <- c.SSEStream(10*time.Millisecond, ch)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/events", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+	served := make(chan struct{})
+	go func() {
+		e.ServeHTTP(w, req)
+		close(served)
+	}()
+	ch <- SSEvent{ID: "1", Data: "hello"}
+	time.Sleep(30 * time.Millisecond)
+	cancel()
+	if !<-gone {
+		t.Fatal("client disconnection is not reported")
+	}
+	<-served
+	body := w.Body.String()
+	if !strings.HasPrefix(body, "id: 1\ndata: hello\n\n") || !strings.Contains(body, ": heartbeat ") {
+		t.Fatalf("unexpected stream %q", body)
+	}
+}
@@ -0,0 +1,274 @@
+package hint
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// 请求超时: 后续的处理链在新的 goroutine 中运行，Req 的 context 带有截止时间，handler 可以通过 c.Done()/c.Req.Context().Done() 感知取消。
+// handler 的响应先写入缓冲区，按时完成时才写出；超时后立即返回 503(或配置的状态码)，之后 handler 的写入都会返回 http.ErrHandlerTimeout。
+// Context 会被连接池复用，超时后 handler 可能仍在运行，因此 handler 使用的是 Context 的副本，Keys 和 Errors 在按时完成时合并回来。
+// Keys 中引用了 Context 的值(例如 Sessions 提供的会话)在复制和合并时重新绑定，Timeout 之前加载的会话在 handler 中 Save 的结果写入副本的响应。
+// 由于响应被缓冲，Flush 和 Hijack 在超时中间件之后不可用，流式响应和 WebSocket 不要使用该中间件，e.g.
+//
+//	api.Use(hint.Timeout(hint.TimeoutConfig{Timeout: 5 * time.Second, StatusCode: http.StatusGatewayTimeout}))
+
+// TimeoutConfig defines the config for Timeout middleware
+type TimeoutConfig struct {
+	Timeout time.Duration
+	// StatusCode is the status of the timeout response, 503 by default
+	StatusCode int
+	// Response writes the timeout response, c.Fail(StatusCode, StatusText) by default
+	Response HandlerFunc
+}
+
+// Timeout returns a middleware which aborts the request with the timeout response when the handlers don't finish in time
+func Timeout(conf TimeoutConfig) HandlerFunc {
+	if conf.Timeout <= 0 {
+		panic("Timeout: Timeout must be positive")
+	}
+	code := conf.StatusCode
+	if code == 0 {
+		code = http.StatusServiceUnavailable
+	}
+	response := conf.Response
+	if response == nil {
+		response = func(c *Context) {
+			c.Fail(code, http.StatusText(code))
+		}
+	}
+
+	return func(c *Context) {
+		ctx, cancel := context.WithTimeout(c.Req.Context(), conf.Timeout)
+		defer cancel()
+
+		tw := &timeoutWriter{ctx: ctx, header: c.Writer.Header().Clone(), status: c.Writer.Status()}
+		cp := c.clone()
+		cp.Req = c.Req.WithContext(ctx)
+		cp.Writer = tw
+
+		done := make(chan struct{})
+		panicCh := make(chan interface{}, 1)
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panicCh <- p
+				}
+				close(done)
+			}()
+			cp.Next()
+		}()
+
+		select {
+		case <-done:
+			select {
+			case p := <-panicCh:
+				// re-panic in the goroutine of the request, so that Recovery can handle it
+				panic(p)
+			default:
+			}
+			c.merge(cp)
+			tw.flushTo(c.Writer)
+		case <-ctx.Done():
+			tw.timeout()
+			c.Error(fmt.Errorf("timeout: %w", ctx.Err())).SetMeta(H{"timeout": conf.Timeout.String()})
+			c.Abort()
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				response(c)
+			}
+			// the handlers may still be running, the panic can't be handled by Recovery anymore
+			go func() {
+				<-done
+				// the request has finished, so the clone removes the files of the form parsed after timeout
+				cp.removeMultipartForm()
+				select {
+				case p := <-panicCh:
+					log.Printf("%s\n\n", trace(fmt.Sprintf("panic after timeout: %v", p)))
+				default:
+				}
+			}()
+		}
+	}
+}
+
+// clone copies the Context for a goroutine, the copy is not pooled
+func (c *Context) clone() *Context {
+	cp := &Context{
+		Req:       c.Req,
+		Path:      c.Path,
+		Method:    c.Method,
+		Params:    append(Params(nil), c.Params...),
+		fullPath:  c.fullPath,
+		handlers:  c.handlers,
+		index:     c.index,
+		Errors:    append(errorMsgs(nil), c.Errors...),
+		e:         c.e,
+		bodyBytes: c.bodyBytes,
+	}
+	c.mu.RLock()
+	if c.Keys != nil {
+		cp.Keys = make(map[string]interface{}, len(c.Keys))
+		for k, v := range c.Keys {
+			cp.Keys[k] = bindContext(v, cp)
+		}
+	}
+	c.mu.RUnlock()
+	return cp
+}
+
+// merge takes the state of a finished clone
+func (c *Context) merge(cp *Context) {
+	c.index = cp.index
+	c.Errors = cp.Errors
+	c.bodyBytes = cp.bodyBytes
+	if cp.multipartForm != nil {
+		c.multipartForm = cp.multipartForm
+	}
+	cp.mu.RLock()
+	c.mu.Lock()
+	c.Keys = cp.Keys
+	for k, v := range c.Keys {
+		c.Keys[k] = bindContext(v, c)
+	}
+	c.mu.Unlock()
+	cp.mu.RUnlock()
+}
+
+// contextBound is implemented by the values of Keys which refer to the Context, e.g. the session
+type contextBound interface {
+	// bindContext returns a copy of the value which refers to c
+	bindContext(c *Context) interface{}
+}
+
+// bindContext rebinds v to c when it refers to the Context, so that the clone never writes to the origin Context
+func bindContext(v interface{}, c *Context) interface{} {
+	if b, ok := v.(contextBound); ok {
+		return b.bindContext(c)
+	}
+	return v
+}
+
+// timeoutWriter buffers the response until the handlers finish
+type timeoutWriter struct {
+	// ctx is the deadline context, the origin ResponseWriter is not referenced as the Context may be reused after timeout
+	ctx context.Context
+
+	mu       sync.Mutex
+	header   http.Header
+	status   int
+	body     bytes.Buffer
+	written  bool
+	timedOut bool
+}
+
+var _ ResponseWriter = (*timeoutWriter)(nil)
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if code > 0 && !w.written && !w.timedOut {
+		w.status = code
+	}
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.written = true
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	w.written = true
+	return w.body.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *timeoutWriter) Status() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.status
+}
+
+func (w *timeoutWriter) Size() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.written {
+		return noWritten
+	}
+	return w.body.Len()
+}
+
+func (w *timeoutWriter) Written() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.written
+}
+
+// Hijack is not supported, as the response is buffered
+func (w *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, errors.New("hint: Hijack is not supported after Timeout middleware")
+}
+
+// Flush does nothing, as the response is buffered
+func (w *timeoutWriter) Flush() {}
+
+// CloseNotify receives when the request is timeout, canceled or finished
+func (w *timeoutWriter) CloseNotify() <-chan bool {
+	ch := make(chan bool, 1)
+	go func() {
+		<-w.ctx.Done()
+		ch <- true
+	}()
+	return ch
+}
+
+func (w *timeoutWriter) Pusher() http.Pusher {
+	return nil
+}
+
+// timeout discards the buffered response, the later writes fail with http.ErrHandlerTimeout
+func (w *timeoutWriter) timeout() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.timedOut = true
+	w.body.Reset()
+}
+
+// flushTo writes the buffered response to dst, it's called after the handlers finish
+// the headers are merged, so that the headers set on dst meanwhile are kept
+func (w *timeoutWriter) flushTo(dst ResponseWriter) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	header := dst.Header()
+	for k, v := range w.header {
+		header[k] = v
+	}
+	dst.WriteHeader(w.status)
+	if w.written {
+		dst.WriteHeaderNow()
+	}
+	if w.body.Len() > 0 {
+		_, _ = io.Copy(dst, &w.body)
+	}
+}
@@ -0,0 +1,81 @@
+package hint
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestTimeout(t *testing.T) {
+	e := New()
+	e.Use(Recovery(), func(c *Context) {
+		c.SetHeader("X-Before", "1")
+		c.Next()
+	}, Timeout(TimeoutConfig{Timeout: 50 * time.Millisecond, StatusCode: http.StatusGatewayTimeout}))
+
+	e.GET("/fast", func(c *Context) {
+		c.Set("user", "hg")
+		c.SetHeader("X-Handler", "1")
+		c.String(http.StatusCreated, "fast")
+	}, func(c *Context) {
+		c.String(http.StatusOK, " %s", c.GetString("user"))
+	})
+	writeErr := make(chan error, 1)
+	e.GET("/slow", func(c *Context) {
+		<-c.Done()
+		if !errors.Is(c.Err(), context.DeadlineExceeded) {
+			t.Errorf("unexpected context error %v", c.Err())
+		}
+		time.Sleep(10 * time.Millisecond)
+		_, err := c.Writer.WriteString("late")
+		writeErr <- err
+	})
+	e.GET("/panic", func(c *Context) {
+		panic("boom")
+	})
+
+	w := performRequest(e, http.MethodGet, "/fast")
+	if w.Code != http.StatusCreated || w.Body.String() != "fast hg" ||
+		w.Header().Get("X-Before") != "1" || w.Header().Get("X-Handler") != "1" {
+		t.Fatalf("unexpected response %d %q %v", w.Code, w.Body.String(), w.Header())
+	}
+
+	w = performRequest(e, http.MethodGet, "/slow")
+	if w.Code != http.StatusGatewayTimeout || w.Header().Get("X-Before") != "1" {
+		t.Fatalf("expect 504, got %d %q", w.Code, w.Body.String())
+	}
+	if err := <-writeErr; !errors.Is(err, http.ErrHandlerTimeout) {
+		t.Fatalf("expect ErrHandlerTimeout of late write, got %v", err)
+	}
+
+	if w = performRequest(e, http.MethodGet, "/panic"); w.Code != http.StatusInternalServerError {
+		t.Fatalf("panic is not recovered, got %d", w.Code)
+	}
+}
+
+func TestTimeoutWithSession(t *testing.T) {
+	e := New()
+	e.Use(Sessions("hint_session", NewCookieStore([]byte("0123456789abcdef0123456789abcdef"))), func(c *Context) {
+		// the session is loaded before Timeout
+		c.Session()
+		c.Next()
+		if user, _ := c.Session().Get("user").(string); user != "hg" {
+			t.Errorf("session is not merged back, got %q", user)
+		}
+	}, Timeout(TimeoutConfig{Timeout: time.Second}))
+	e.GET("/login", func(c *Context) {
+		s := c.Session()
+		s.Set("user", "hg")
+		if err := s.Save(); err != nil {
+			t.Error(err)
+		}
+		c.String(http.StatusOK, "ok")
+	})
+
+	w := performRequest(e, http.MethodGet, "/login")
+	if w.Code != http.StatusOK || len(w.Result().Cookies()) != 1 || w.Result().Cookies()[0].Name != "hint_session" {
+		t.Fatalf("session cookie is lost, got %d %v", w.Code, w.Header())
+	}
+}
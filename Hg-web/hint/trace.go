@@ -0,0 +1,172 @@
+package hint
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// 请求 ID 与 W3C Trace Context(https://www.w3.org/TR/trace-context/)，用于关联一个请求在各个服务中的处理。
+// 请求带有 X-Request-ID 时沿用，否则生成一个，并在响应中返回。
+// traceparent 格式为 version-trace_id-parent_id-flags，e.g. 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+// 沿用请求的 trace_id(没有或者无效时生成新的)，为本次处理生成新的 span id，响应和下游调用的 traceparent 中 parent_id 为该 span id。
+// tracestate 原样传递。
+// 调用下游服务(例如 hintrpc、hintcache)时，把 Context 作为 context.Context 传递，或者通过 TraceContext.Inject 写入请求头，e.g.
+//
+//	req, _ := http.NewRequestWithContext(c, http.MethodGet, url, nil)
+//	c.TraceContext().Inject(req.Header)
+
+const (
+	// RequestIDKey is the key of the request id in Context.Keys
+	RequestIDKey = "request_id"
+	// TraceContextKey is the key of *TraceContext in Context.Keys
+	TraceContextKey = "trace_context"
+
+	headerTraceParent = "traceparent"
+	headerTraceState  = "tracestate"
+	// maxRequestIDLength limits the accepted X-Request-ID
+	maxRequestIDLength = 128
+)
+
+// TraceContext is the W3C trace context of a request
+type TraceContext struct {
+	// TraceID is 32 lowercase hex characters
+	TraceID string
+	// ParentID is the span id of the caller, "" when the trace starts here
+	ParentID string
+	// SpanID is the span id of the current request, 16 lowercase hex characters
+	SpanID string
+	// Flags is the trace flags, 01 means sampled
+	Flags string
+	// State is the vendor-specific tracestate, passed through as is
+	State string
+}
+
+// TraceParent returns the traceparent header value whose parent_id is SpanID
+func (tc *TraceContext) TraceParent() string {
+	return "00-" + tc.TraceID + "-" + tc.SpanID + "-" + tc.Flags
+}
+
+// Sampled reports whether the sampled flag is set
+func (tc *TraceContext) Sampled() bool {
+	b, err := hex.DecodeString(tc.Flags)
+	return err == nil && len(b) == 1 && b[0]&1 == 1
+}
+
+// Inject writes traceparent and tracestate to the headers of a downstream request
+func (tc *TraceContext) Inject(h http.Header) {
+	h.Set(headerTraceParent, tc.TraceParent())
+	if tc.State != "" {
+		h.Set(headerTraceState, tc.State)
+	}
+}
+
+// TraceConfig defines the config for Trace middleware
+type TraceConfig struct {
+	// RequestIDHeader is the header of the request id, "X-Request-ID" by default
+	RequestIDHeader string
+	// RequestIDGenerator generates the request id when the request doesn't carry one, 32 random hex characters by default
+	RequestIDGenerator func() string
+	// Sampled sets the sampled flag for the traces started here
+	Sampled bool
+}
+
+// Trace returns a middleware which accepts or generates the request id and the trace context
+// they are set with RequestIDKey and TraceContextKey, and echoed in the response headers
+func Trace(conf TraceConfig) HandlerFunc {
+	header := conf.RequestIDHeader
+	if header == "" {
+		header = "X-Request-ID"
+	}
+	generator := conf.RequestIDGenerator
+	if generator == nil {
+		generator = func() string { return randomHex(16) }
+	}
+	flags := "00"
+	if conf.Sampled {
+		flags = "01"
+	}
+
+	return func(c *Context) {
+		id := c.Req.Header.Get(header)
+		if !validRequestID(id) {
+			id = generator()
+		}
+		tc, ok := parseTraceParent(c.Req.Header.Get(headerTraceParent))
+		if ok {
+			tc.State = strings.TrimSpace(strings.Join(c.Req.Header.Values(headerTraceState), ","))
+		} else {
+			tc = &TraceContext{TraceID: randomHex(16), Flags: flags}
+		}
+		tc.SpanID = randomHex(8)
+
+		c.Set(RequestIDKey, id)
+		c.Set(TraceContextKey, tc)
+		h := c.Writer.Header()
+		h.Set(header, id)
+		tc.Inject(h)
+		c.Next()
+	}
+}
+
+// RequestID returns the request id set by Trace middleware, "" when it's absent
+func (c *Context) RequestID() string {
+	return c.GetString(RequestIDKey)
+}
+
+// TraceContext returns the trace context set by Trace middleware, nil when it's absent
+func (c *Context) TraceContext() *TraceContext {
+	v, _ := c.Get(TraceContextKey)
+	tc, _ := v.(*TraceContext)
+	return tc
+}
+
+// validRequestID accepts the printable ASCII id not longer than maxRequestIDLength
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// parseTraceParent parses the traceparent header, versions higher than 00 are parsed by the fields of 00
+func parseTraceParent(value string) (*TraceContext, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 {
+		return nil, false
+	}
+	version, traceID, parentID, flags := parts[0], parts[1], parts[2], parts[3]
+	if !isLowerHex(version, 2) || version == "ff" || (version == "00" && len(parts) != 4) {
+		return nil, false
+	}
+	if !isLowerHex(traceID, 32) || traceID == strings.Repeat("0", 32) ||
+		!isLowerHex(parentID, 16) || parentID == strings.Repeat("0", 16) || !isLowerHex(flags, 2) {
+		return nil, false
+	}
+	return &TraceContext{TraceID: traceID, ParentID: parentID, Flags: flags}, true
+}
+
+func isLowerHex(s string, length int) bool {
+	if len(s) != length {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !('0' <= s[i] && s[i] <= '9' || 'a' <= s[i] && s[i] <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// randomHex returns n random bytes in hex
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
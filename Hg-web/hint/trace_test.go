@@ -0,0 +1,73 @@
+package hint
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	tests := []struct {
+		value string
+		ok    bool
+	}{
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true},
+		{"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future", true},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", false},
+		{"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false},
+		{"00-00000000000000000000000000000000-00f067aa0ba902b7-01", false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false},
+		{"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if _, ok := parseTraceParent(tt.value); ok != tt.ok {
+			t.Errorf("%q: expect %v, got %v", tt.value, tt.ok, ok)
+		}
+	}
+}
+
+func TestTrace(t *testing.T) {
+	var buf bytes.Buffer
+	e := New()
+	e.Use(LoggerWithConfig(LoggerConfig{Format: LogFormatJSON, Output: &buf}), Trace(TraceConfig{}))
+	e.GET("/", func(c *Context) {
+		c.String(http.StatusOK, "%s %s", c.RequestID(), c.TraceContext().TraceID)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set("tracestate", "vendor=value")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+
+	if w.Body.String() != "req-1 4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("unexpected body %q", w.Body.String())
+	}
+	if w.Header().Get("X-Request-ID") != "req-1" || w.Header().Get("tracestate") != "vendor=value" {
+		t.Fatalf("unexpected response headers %v", w.Header())
+	}
+	tc, ok := parseTraceParent(w.Header().Get("traceparent"))
+	if !ok || tc.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || tc.ParentID == "00f067aa0ba902b7" || tc.Flags != "01" {
+		t.Fatalf("unexpected traceparent %q", w.Header().Get("traceparent"))
+	}
+	if !strings.Contains(buf.String(), `"request_id":"req-1","trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"`) {
+		t.Fatalf("request id is not logged: %q", buf.String())
+	}
+
+	// invalid ids are replaced
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Request-ID", "bad id")
+	req.Header.Set("traceparent", "invalid")
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	if id := w.Header().Get("X-Request-ID"); len(id) != 32 {
+		t.Fatalf("request id is not generated, got %q", id)
+	}
+	if tc, ok = parseTraceParent(w.Header().Get("traceparent")); !ok || tc.Flags != "00" {
+		t.Fatalf("trace is not started, got %q", w.Header().Get("traceparent"))
+	}
+}
@@ -0,0 +1,175 @@
+package hint
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HTTP请求的路径恰好是由/分隔的多段构成的，因此，每一段可以作为前缀树的一个节点。
+// 但是按段存储时，每次查询都要先把路径切分成多段，会产生内存分配。
+// 压缩前缀树(radix tree)把只有一个子节点的静态路径合并成一条边，例如 /hello/ 与 /help 共享前缀 /hel，
+// 查询时直接在原始路径上逐段比较前缀，不再切分路径，也不再通过 "METHOD-pattern" 查找 handler。
+//
+// 参数匹配":"，例如 /p/:lang/doc，可以匹配 /p/c/doc 和 /p/go/doc。
+// 通配"*"，例如 /static/*filepath，可以匹配/static/fav.ico，也可以匹配/static/js/jQuery.js，这种模式常用于静态服务器，能够递归地匹配子路径。
+//
+// 匹配优先级: 静态 > 参数":" > 通配"*"，与注册顺序无关。
+// 同一位置只允许存在一个参数节点和一个通配节点，名称不同的同层参数(例如 /:id 与 /:name)在注册时直接 panic。
+
+// Param is a single URL parameter, consisting of a key and a value
+type Param struct {
+	Key   string
+	Value string
+}
+
+// Params is a Param-slice, as returned by the router
+// the slice is ordered, the first URL parameter is also the first slice value
+type Params []Param
+
+// Get returns the value of the first Param which key matches the given name
+func (ps Params) Get(name string) (string, bool) {
+	for _, p := range ps {
+		if p.Key == name {
+			return p.Value, true
+		}
+	}
+	return "", false
+}
+
+// ByName returns the value of the first Param which key matches the given name, "" when not found
+func (ps Params) ByName(name string) string {
+	value, _ := ps.Get(name)
+	return value
+}
+
+type node struct {
+	path     string        // static part of the edge e.g. /hel, or the wildcard e.g. :lang *filepath
+	indices  string        // first byte of each static child, children[i].path starts with indices[i]
+	children []*node       // static children
+	param    *node         // ":" child, at most one
+	catchAll *node         // "*" child, at most one
+	pattern  string        // full pattern of router e.g. /p/:lang (not nil when the path fulled)
+	handlers []HandlerFunc // full handler chain of pattern, group middlewares included
+}
+
+// wildcardIndex returns the index of the first ":" or "*" starting a path segment, len(path) when not found
+func wildcardIndex(path string) int {
+	for i := 1; i < len(path); i++ {
+		if (path[i] == ':' || path[i] == '*') && path[i-1] == '/' {
+			return i
+		}
+	}
+	return len(path)
+}
+
+// longestCommonPrefix returns the length of the common prefix of a and b
+func longestCommonPrefix(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+// insert pattern, path is the part of pattern which is not consumed yet
+// panic when pattern is already registered or conflicts with an existing wildcard
+func (n *node) insert(path string, pattern string, handlers []HandlerFunc) {
+	for path != "" {
+		switch path[0] {
+		case ':':
+			end := strings.IndexByte(path, '/')
+			if end < 0 {
+				end = len(path)
+			}
+			if n.param == nil {
+				n.param = &node{path: path[:end]}
+			} else if n.param.path != path[:end] {
+				panic(fmt.Sprintf("wildcard '%s' in route '%s' conflicts with existing wildcard '%s'", path[:end], pattern, n.param.path))
+			}
+			n, path = n.param, path[end:]
+		case '*':
+			if n.catchAll == nil {
+				n.catchAll = &node{path: path}
+			} else if n.catchAll.path != path {
+				panic(fmt.Sprintf("wildcard '%s' in route '%s' conflicts with existing wildcard '%s'", path, pattern, n.catchAll.path))
+			}
+			n, path = n.catchAll, ""
+		default:
+			end := wildcardIndex(path)
+			n, path = n.insertStatic(path[:end]), path[end:]
+		}
+	}
+	if n.pattern != "" {
+		panic(fmt.Sprintf("route '%s' conflicts with existing route '%s'", pattern, n.pattern))
+	}
+	n.pattern = pattern
+	n.handlers = handlers
+}
+
+// insertStatic inserts the static path s below n and returns the node where s ends
+// an existing edge is split when s shares only a part of it
+// e.g. insert /help below /hello/ -> /hel + [lo/, p]
+func (n *node) insertStatic(s string) *node {
+	for s != "" {
+		i := strings.IndexByte(n.indices, s[0])
+		if i < 0 {
+			child := &node{path: s}
+			n.indices += s[:1]
+			n.children = append(n.children, child)
+			return child
+		}
+		child := n.children[i]
+		l := longestCommonPrefix(s, child.path)
+		if l < len(child.path) {
+			rest := *child
+			rest.path = child.path[l:]
+			*child = node{
+				path:     child.path[:l],
+				indices:  rest.path[:1],
+				children: []*node{&rest},
+			}
+		}
+		n, s = child, s[l:]
+	}
+	return n
+}
+
+// search path below n (n itself is matched), the values of wildcards are appended to params
+// static children are tried first, then ":" param, then "*" catch-all
+// params is not allocated when it has enough capacity
+func (n *node) search(path string, params *Params) *node {
+	if path == "" {
+		if n.pattern == "" {
+			return nil
+		}
+		return n
+	}
+	if i := strings.IndexByte(n.indices, path[0]); i >= 0 {
+		child := n.children[i]
+		if len(path) >= len(child.path) && path[:len(child.path)] == child.path {
+			if res := child.search(path[len(child.path):], params); res != nil {
+				return res
+			}
+		}
+	}
+	if n.param != nil {
+		end := strings.IndexByte(path, '/')
+		if end < 0 {
+			end = len(path)
+		}
+		if end > 0 {
+			*params = append(*params, Param{Key: n.param.path[1:], Value: path[:end]})
+			if res := n.param.search(path[end:], params); res != nil {
+				return res
+			}
+			*params = (*params)[:len(*params)-1]
+		}
+	}
+	if n.catchAll != nil {
+		if len(n.catchAll.path) > 1 {
+			*params = append(*params, Param{Key: n.catchAll.path[1:], Value: path})
+		}
+		return n.catchAll
+	}
+	return nil
+}
@@ -37,10 +37,132 @@ func TestGetRoute(t *testing.T) {
 		t.Fatal("should match /hello/:name")
 	}
 
-	if ps["name"] != "hb" {
+	if ps.ByName("name") != "hb" {
 		t.Fatal("name should be equal to 'hb'")
 	}
 
-	fmt.Printf("matched path: %s, params['name']: %s\n", n.pattern, ps["name"])
+	fmt.Printf("matched path: %s, params['name']: %s\n", n.pattern, ps.ByName("name"))
 
 }
+
+func TestGetRouteEmptySegments(t *testing.T) {
+	r := newTestRouter()
+	tests := []struct {
+		path    string
+		pattern string
+		param   string
+	}{
+		{"//", "/", ""},
+		{"/hello//hb", "/hello/:name", "hb"},
+		{"//hello/b//c/", "/hello/b/c", ""},
+		{"/assets//css///a.css", "/assets/*filepath", "css/a.css"},
+	}
+	for _, tt := range tests {
+		n, ps := r.getRoute("GET", tt.path)
+		if n == nil || n.pattern != tt.pattern {
+			t.Fatalf("%s: expect %s, got %v", tt.path, tt.pattern, n)
+		}
+		if len(ps) > 0 && ps[0].Value != tt.param {
+			t.Fatalf("%s: expect param %q, got %q", tt.path, tt.param, ps[0].Value)
+		}
+	}
+}
+
+func TestRoutePriority(t *testing.T) {
+	r := newRouter()
+	// registration order must not affect the lookup priority
+	for _, p := range []string{
+		"/hello/:name",
+		"/hello/b",
+		"/hello/b/c",
+		"/hello/:name/info",
+		"/static/*filepath",
+		"/static/:file/raw",
+		"/static/index",
+		"/p/:lang/doc",
+		"/p/go/*rest",
+		"/help",
+		"/hel",
+	} {
+		r.addRouter("GET", p, nil)
+	}
+
+	tests := []struct {
+		path    string
+		pattern string
+		params  map[string]string
+	}{
+		{"/hello/b", "/hello/b", map[string]string{}},
+		{"/hello/a", "/hello/:name", map[string]string{"name": "a"}},
+		{"/hello/b/c", "/hello/b/c", map[string]string{}},
+		{"/hello/b/info", "/hello/:name/info", map[string]string{"name": "b"}},
+		{"/static/index", "/static/index", map[string]string{}},
+		{"/static/logo/raw", "/static/:file/raw", map[string]string{"file": "logo"}},
+		{"/static/logo", "/static/*filepath", map[string]string{"filepath": "logo"}},
+		{"/static/css/hg.css", "/static/*filepath", map[string]string{"filepath": "css/hg.css"}},
+		{"/p/c/doc", "/p/:lang/doc", map[string]string{"lang": "c"}},
+		// static "go" beats ":lang" at the same depth
+		{"/p/go/doc", "/p/go/*rest", map[string]string{"rest": "doc"}},
+		{"/p/go/src/fmt", "/p/go/*rest", map[string]string{"rest": "src/fmt"}},
+		{"/help", "/help", map[string]string{}},
+		{"/hel", "/hel", map[string]string{}},
+		{"/hell", "", nil},
+		{"/hello/b/", "/hello/b", map[string]string{}},
+		{"/p/c/src", "", nil},
+		{"/hello/b/c/d", "", nil},
+	}
+	for _, tt := range tests {
+		n, ps := r.getRoute("GET", tt.path)
+		if tt.pattern == "" {
+			if n != nil {
+				t.Errorf("%s: expect no match, got %s", tt.path, n.pattern)
+			}
+			continue
+		}
+		if n == nil {
+			t.Errorf("%s: expect %s, got no match", tt.path, tt.pattern)
+			continue
+		}
+		if n.pattern != tt.pattern {
+			t.Errorf("%s: expect %s, got %s", tt.path, tt.pattern, n.pattern)
+		}
+		got := make(map[string]string)
+		for _, p := range ps {
+			got[p.Key] = p.Value
+		}
+		if !reflect.DeepEqual(got, tt.params) {
+			t.Errorf("%s: expect params %v, got %v", tt.path, tt.params, got)
+		}
+	}
+}
+
+func TestRouteConflicts(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		conflict bool
+	}{
+		{"static and param", []string{"/hello/:name", "/hello/b"}, false},
+		{"param and catch-all", []string{"/src/:file", "/src/*filepath"}, false},
+		{"same param name", []string{"/user/:id", "/user/:id/info"}, false},
+		{"different param names", []string{"/user/:id", "/user/:name/info"}, true},
+		{"different catch-all names", []string{"/src/*filepath", "/src/*path"}, true},
+		{"duplicated route", []string{"/user/:id", "/user/:id"}, true},
+		{"duplicated after slash cleaning", []string{"/user", "/user/"}, true},
+		{"catch-all not at the end", []string{"/src/*filepath/info"}, true},
+		{"unnamed param", []string{"/user/:"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if err := recover(); (err != nil) != tt.conflict {
+					t.Fatalf("expect conflict %v, got panic %v", tt.conflict, err)
+				}
+			}()
+			r := newRouter()
+			for _, p := range tt.patterns {
+				r.addRouter("GET", p, nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package hint
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// 文件上传: multipart 表单由 Context.MultipartForm 解析，不超过 Engine.MaxMultipartMemory 的部分保存在内存中，其余写入临时文件，e.g.
+//
+//	file, err := c.FormFile("avatar")
+//	if err != nil {
+//		c.Fail(http.StatusBadRequest, err.Error())
+//		return
+//	}
+//	err = c.SaveUploadedFile(file, filepath.Join("uploads", filepath.Base(file.Filename)))
+//
+// 大文件可以通过 MultipartReader 逐个读取 part 直接写到磁盘，不经过内存和临时文件，e.g.
+//
+//	mr, _ := c.MultipartReader()
+//	for part, err := mr.NextPart(); err == nil; part, err = mr.NextPart() {
+//		_, err = c.SaveUploadedPart(part, filepath.Join("uploads", filepath.Base(part.FileName())))
+//	}
+//
+// MultipartForm 解析出的临时文件在请求结束时删除。net/http 只清理它自己的 *http.Request，
+// c.Req 被替换(例如 Timeout 中间件的 c.Req.WithContext)后解析出的临时文件需要由框架清理。
+//
+// BodyLimit 中间件限制单个路由的请求体大小，超过时返回 413，e.g. e.POST("/upload", hint.BodyLimit(10<<20), upload)
+
+// BodyLimit returns a middleware which limits the request body to limit bytes
+// the request is rejected with 413 when Content-Length exceeds the limit, otherwise reading more than limit fails
+// with *http.MaxBytesError, Bind turns it into 413 and other readers should check it with errors.As
+func BodyLimit(limit int64) HandlerFunc {
+	return func(c *Context) {
+		if c.Req.ContentLength > limit {
+			c.Fail(http.StatusRequestEntityTooLarge, "request body is larger than "+strconv.FormatInt(limit, 10)+" bytes")
+			return
+		}
+		if c.Req.Body != nil {
+			c.Req.Body = http.MaxBytesReader(originWriter(c.Writer), c.Req.Body, limit)
+		}
+		c.Next()
+	}
+}
+
+// originWriter returns the http.ResponseWriter passed in by the server, so that MaxBytesReader can tell
+// net/http to close the connection after the body exceeds the limit
+func originWriter(w http.ResponseWriter) http.ResponseWriter {
+	for {
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return w
+		}
+		w = u.Unwrap()
+	}
+}
+
+// bindErrorStatus returns 413 when the body exceeds the limit of BodyLimit, otherwise 400
+func bindErrorStatus(err error) int {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// MultipartForm parses the multipart form with Engine.MaxMultipartMemory, including the uploaded files
+// the temporary files of the form are removed when the handler chain finishes
+func (c *Context) MultipartForm() (*multipart.Form, error) {
+	maxMemory := int64(defaultMemory)
+	if c.e != nil && c.e.MaxMultipartMemory > 0 {
+		maxMemory = c.e.MaxMultipartMemory
+	}
+	if err := c.Req.ParseMultipartForm(maxMemory); err != nil {
+		return nil, err
+	}
+	c.multipartForm = c.Req.MultipartForm
+	return c.Req.MultipartForm, nil
+}
+
+// removeMultipartForm removes the temporary files of the form parsed by MultipartForm
+func (c *Context) removeMultipartForm() {
+	if c.multipartForm != nil {
+		_ = c.multipartForm.RemoveAll()
+		c.multipartForm = nil
+	}
+}
+
+// FormFile returns the first file of the form field name
+func (c *Context) FormFile(name string) (*multipart.FileHeader, error) {
+	if c.Req.MultipartForm == nil {
+		if _, err := c.MultipartForm(); err != nil {
+			return nil, err
+		}
+	}
+	f, fh, err := c.Req.FormFile(name)
+	if err != nil {
+		return nil, err
+	}
+	_ = f.Close()
+	return fh, nil
+}
+
+// SaveUploadedFile saves the uploaded file to dst, the parent directories are created when needed
+// dst must not be derived from file.Filename directly, use filepath.Base to avoid path traversal
+func (c *Context) SaveUploadedFile(file *multipart.FileHeader, dst string) error {
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	_, err = saveToFile(src, dst)
+	return err
+}
+
+// MultipartReader returns a reader of the multipart body, the parts are read one by one without buffering
+// it can't be used together with MultipartForm or FormFile
+func (c *Context) MultipartReader() (*multipart.Reader, error) {
+	return c.Req.MultipartReader()
+}
+
+// SaveUploadedPart streams the part read from MultipartReader to dst, returns the number of bytes written
+// the partially written file is removed when it fails, e.g. the body exceeds the limit of BodyLimit
+func (c *Context) SaveUploadedPart(part *multipart.Part, dst string) (int64, error) {
+	return saveToFile(part, dst)
+}
+
+func saveToFile(src io.Reader, dst string) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o750); err != nil {
+		return 0, err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(out, src)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(dst)
+	}
+	return n, err
+}
@@ -0,0 +1,228 @@
+package hint
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// multipartBody returns a body with a name field and a file field named avatar
+func multipartBody(t *testing.T, content string) (*bytes.Buffer, string) {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	mw := multipart.NewWriter(buf)
+	_ = mw.WriteField("name", "hg")
+	fw, err := mw.CreateFormFile("avatar", "avatar.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = fw.Write([]byte(content))
+	_ = mw.Close()
+	return buf, mw.FormDataContentType()
+}
+
+func TestFormFileAndSaveUploadedFile(t *testing.T) {
+	dir := t.TempDir()
+	e := New()
+	// files larger than 1 byte are stored in temporary files
+	e.MaxMultipartMemory = 1
+	e.POST("/upload", func(c *Context) {
+		file, err := c.FormFile("avatar")
+		if err != nil {
+			c.Fail(http.StatusBadRequest, err.Error())
+			return
+		}
+		if err = c.SaveUploadedFile(file, filepath.Join(dir, "sub", filepath.Base(file.Filename))); err != nil {
+			c.Fail(http.StatusInternalServerError, err.Error())
+			return
+		}
+		form, _ := c.MultipartForm()
+		c.String(http.StatusOK, "%s %d", form.Value["name"][0], file.Size)
+	})
+
+	body, contentType := multipartBody(t, "png data")
+	req := httptest.NewRequest(http.MethodPost, "/upload", body)
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "hg 8" {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "sub", "avatar.png")); err != nil || string(data) != "png data" {
+		t.Fatalf("unexpected file %q, %v", data, err)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("name=hg"))
+	req.Header.Set("Content-Type", MIMEPOSTForm)
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status %d", w.Code)
+	}
+}
+
+func TestMultipartFormTempFilesRemoved(t *testing.T) {
+	e := New()
+	e.MaxMultipartMemory = 1
+	var tmpFiles []string
+	handler := func(c *Context) {
+		file, err := c.FormFile("avatar")
+		if err != nil {
+			c.Fail(http.StatusBadRequest, err.Error())
+			return
+		}
+		f, _ := file.Open()
+		defer f.Close()
+		if osFile, ok := f.(*os.File); ok {
+			tmpFiles = append(tmpFiles, osFile.Name())
+		}
+		c.String(http.StatusOK, "ok")
+	}
+	// c.Req is replaced, so net/http doesn't remove the files of the parsed form
+	e.POST("/upload", func(c *Context) {
+		c.Req = c.Req.WithContext(c.Req.Context())
+	}, handler)
+	e.POST("/timeout", Timeout(TimeoutConfig{Timeout: time.Second}), handler)
+
+	for _, path := range []string{"/upload", "/timeout"} {
+		body, contentType := multipartBody(t, "png data")
+		req := httptest.NewRequest(http.MethodPost, path, body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+		}
+	}
+	if len(tmpFiles) != 2 {
+		t.Fatalf("expect 2 temporary files, got %v", tmpFiles)
+	}
+	for _, name := range tmpFiles {
+		if _, err := os.Stat(name); !os.IsNotExist(err) {
+			t.Errorf("temporary file %s is not removed: %v", name, err)
+		}
+	}
+}
+
+func TestBodyLimit(t *testing.T) {
+	type form struct {
+		Name   string                `form:"name"`
+		Avatar *multipart.FileHeader `form:"avatar" binding:"required"`
+	}
+	e := New()
+	e.POST("/upload", BodyLimit(512), func(c *Context) {
+		var f form
+		if c.Bind(&f) != nil {
+			return
+		}
+		c.String(http.StatusOK, "%s %d", f.Name, f.Avatar.Size)
+	})
+	send := func(content string, chunked bool) *httptest.ResponseRecorder {
+		body, contentType := multipartBody(t, content)
+		req := httptest.NewRequest(http.MethodPost, "/upload", body)
+		if chunked {
+			// the length is unknown, so the limit is checked while reading
+			req.Body = io.NopCloser(body)
+			req.ContentLength = -1
+		}
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := send("small", false); w.Code != http.StatusOK || w.Body.String() != "hg 5" {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+	}
+	if w := send(strings.Repeat("x", 1024), false); w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("unexpected status %d", w.Code)
+	}
+	if w := send(strings.Repeat("x", 1024), true); w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("unexpected status %d", w.Code)
+	}
+
+	// the server closes the connection after the oversized body, even when the writer is wrapped by Compress
+	e.POST("/compress", Compress(CompressConfig{}), BodyLimit(512), func(c *Context) {
+		_, err := c.GetRawData()
+		c.Status(bindErrorStatus(err))
+	})
+	ts := httptest.NewServer(e)
+	defer ts.Close()
+	for _, path := range []string{"/upload", "/compress"} {
+		body, contentType := multipartBody(t, strings.Repeat("x", 1024))
+		// io.MultiReader hides the length, so the body is sent chunked
+		resp, err := http.Post(ts.URL+path, contentType, io.MultiReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusRequestEntityTooLarge || !resp.Close {
+			t.Fatalf("%s: expect 413 with Connection: close, got %d %v", path, resp.StatusCode, resp.Header)
+		}
+	}
+}
+
+func TestSaveUploadedPart(t *testing.T) {
+	dir := t.TempDir()
+	e := New()
+	e.POST("/upload", BodyLimit(1024), func(c *Context) {
+		mr, err := c.MultipartReader()
+		if err != nil {
+			c.Fail(http.StatusBadRequest, err.Error())
+			return
+		}
+		var saved []string
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				c.Fail(bindErrorStatus(err), err.Error())
+				return
+			}
+			if part.FileName() == "" {
+				continue
+			}
+			n, err := c.SaveUploadedPart(part, filepath.Join(dir, filepath.Base(part.FileName())))
+			if err != nil {
+				c.Fail(bindErrorStatus(err), err.Error())
+				return
+			}
+			saved = append(saved, part.FormName()+"="+strings.Repeat("#", int(n)))
+		}
+		c.String(http.StatusOK, strings.Join(saved, ","))
+	})
+	send := func(content string) *httptest.ResponseRecorder {
+		body, contentType := multipartBody(t, content)
+		req := httptest.NewRequest(http.MethodPost, "/upload", io.NopCloser(body))
+		req.ContentLength = -1
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := send("data"); w.Code != http.StatusOK || w.Body.String() != "avatar=####" {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "avatar.png")); err != nil || string(data) != "data" {
+		t.Fatalf("unexpected file %q, %v", data, err)
+	}
+
+	// the partial file is removed
+	_ = os.Remove(filepath.Join(dir, "avatar.png"))
+	if w := send(strings.Repeat("x", 4096)); w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("unexpected status %d", w.Code)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "avatar.png")); !os.IsNotExist(err) {
+		t.Fatalf("partial file is not removed: %v", err)
+	}
+}
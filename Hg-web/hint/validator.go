@@ -0,0 +1,240 @@
+package hint
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// 声明式校验，规则写在 `binding` tag 中，多个规则以 "," 分隔，例如 `binding:"required,min=3,max=32"`。
+// 支持的规则:
+//   required        字段不能为零值(字符串、切片、map 不能为空)
+//   omitempty       字段为零值时跳过后续规则
+//   min=n / max=n   数字比较数值，字符串比较字符数，切片和 map 比较长度
+//   len=n           同上，要求相等
+//   oneof=a b c     值必须是列出的其中之一
+//   email           必须是合法的邮箱地址
+//   regexp=expr     必须匹配正则，regexp 必须是最后一个规则，因为表达式中可能包含 ","
+// 嵌套的结构体(包括指针与切片中的结构体)会递归校验。
+
+// FieldError describes a single field which failed the validation
+type FieldError struct {
+	Field string      // namespace of the field e.g. User.Address.City
+	Tag   string      // failed rule e.g. min
+	Param string      // param of the rule e.g. 3 for min=3
+	Value interface{} // actual value of the field
+}
+
+func (fe FieldError) Error() string {
+	if fe.Param == "" {
+		return fmt.Sprintf("field '%s' failed on the '%s' rule", fe.Field, fe.Tag)
+	}
+	return fmt.Sprintf("field '%s' failed on the '%s=%s' rule", fe.Field, fe.Tag, fe.Param)
+}
+
+// ValidationErrors is the list of FieldError returned by binding
+type ValidationErrors []FieldError
+
+func (ve ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(ve))
+	for _, fe := range ve {
+		msgs = append(msgs, fe.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+var (
+	emailRegexp = regexp.MustCompile(`^[a-zA-Z0-9.!#$%&'*+/=?^_{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)+$`)
+	regexpCache sync.Map // compiled regexp of rule "regexp=", key is the expression
+)
+
+// validate checks obj by `binding` tag, returns ValidationErrors when any field is invalid
+func validate(obj interface{}) error {
+	var errs ValidationErrors
+	validateValue(reflect.ValueOf(obj), "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validateValue(v reflect.Value, ns string, errs *ValidationErrors) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return
+		}
+		if ns == "" {
+			ns = v.Type().Name()
+		}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if sf.PkgPath != "" && !sf.Anonymous {
+				continue
+			}
+			fieldNs := sf.Name
+			if ns != "" {
+				fieldNs = ns + "." + sf.Name
+			}
+			if rules := sf.Tag.Get("binding"); rules != "" && rules != "-" {
+				validateField(v.Field(i), fieldNs, rules, errs)
+			}
+			validateValue(v.Field(i), fieldNs, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		elem := v.Type().Elem()
+		for elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		if elem.Kind() != reflect.Struct && elem.Kind() != reflect.Interface {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			validateValue(v.Index(i), fmt.Sprintf("%s[%d]", ns, i), errs)
+		}
+	}
+}
+
+// validateField checks the rules of a single field, stops at the first failed rule
+func validateField(v reflect.Value, ns string, rules string, errs *ValidationErrors) {
+	for rules != "" {
+		var rule string
+		if strings.HasPrefix(rules, "regexp=") {
+			rule, rules = rules, ""
+		} else if i := strings.IndexByte(rules, ','); i >= 0 {
+			rule, rules = rules[:i], rules[i+1:]
+		} else {
+			rule, rules = rules, ""
+		}
+		tag, param := rule, ""
+		if i := strings.IndexByte(rule, '='); i >= 0 {
+			tag, param = rule[:i], rule[i+1:]
+		}
+		if tag == "omitempty" {
+			if isEmpty(v) {
+				return
+			}
+			continue
+		}
+		if !checkRule(v, tag, param) {
+			*errs = append(*errs, FieldError{Field: ns, Tag: tag, Param: param, Value: fieldValue(v)})
+			return
+		}
+	}
+}
+
+func fieldValue(v reflect.Value) interface{} {
+	if v.CanInterface() {
+		return v.Interface()
+	}
+	return nil
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	default:
+		return v.IsZero()
+	}
+}
+
+// checkRule panics when the rule is unknown or the param is invalid, as it is a mistake of the struct definition
+func checkRule(v reflect.Value, tag string, param string) bool {
+	if tag != "required" {
+		// rules other than required are applied to the value that the pointer points to
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return true
+			}
+			v = v.Elem()
+		}
+	}
+	switch tag {
+	case "required":
+		return !isEmpty(v)
+	case "min":
+		return compare(v, tag, param) >= 0
+	case "max":
+		return compare(v, tag, param) <= 0
+	case "len":
+		return compare(v, tag, param) == 0
+	case "oneof":
+		s := fmt.Sprint(v.Interface())
+		for _, option := range strings.Fields(param) {
+			if s == option {
+				return true
+			}
+		}
+		return false
+	case "email":
+		return v.Kind() == reflect.String && emailRegexp.MatchString(v.String())
+	case "regexp":
+		re, ok := regexpCache.Load(param)
+		if !ok {
+			re, _ = regexpCache.LoadOrStore(param, regexp.MustCompile(param))
+		}
+		return v.Kind() == reflect.String && re.(*regexp.Regexp).MatchString(v.String())
+	default:
+		panic(fmt.Sprintf("binding: unknown validation rule '%s'", tag))
+	}
+}
+
+// compare returns -1, 0, 1 when the value (or the length) of v is less than, equal to, greater than param
+func compare(v reflect.Value, tag string, param string) int {
+	switch v.Kind() {
+	case reflect.String:
+		return compareInt(int64(utf8.RuneCountInString(v.String())), tag, param)
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return compareInt(int64(v.Len()), tag, param)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareInt(v.Int(), tag, param)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return compareFloat(float64(v.Uint()), tag, param)
+	case reflect.Float32, reflect.Float64:
+		return compareFloat(v.Float(), tag, param)
+	default:
+		panic(fmt.Sprintf("binding: rule '%s' is not supported by %s", tag, v.Type()))
+	}
+}
+
+func compareInt(n int64, tag string, param string) int {
+	p, err := strconv.ParseInt(param, 10, 64)
+	if err != nil {
+		panic(fmt.Sprintf("binding: invalid param of rule '%s=%s'", tag, param))
+	}
+	switch {
+	case n < p:
+		return -1
+	case n > p:
+		return 1
+	}
+	return 0
+}
+
+func compareFloat(f float64, tag string, param string) int {
+	p, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		panic(fmt.Sprintf("binding: invalid param of rule '%s=%s'", tag, param))
+	}
+	switch {
+	case f < p:
+		return -1
+	case f > p:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,714 @@
+package hint
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// WebSocket(RFC 6455)，不依赖第三方库。
+// 握手: 客户端发送带有 Upgrade: websocket 的 GET 请求，服务端校验后返回 101，并用 Sec-WebSocket-Accept 证明理解了 WebSocket 协议，
+// 之后通过 Hijack 接管 TCP 连接，双方以帧(frame)为单位通信:
+//
+//	FIN(1) RSV1-3(3) opcode(4) | MASK(1) payload len(7) | 扩展长度(16/64) | mask key(32) | payload
+//
+// 一条消息可以分成多个帧(分片)，控制帧(close/ping/pong)可以插在分片之间。客户端发送的帧必须掩码，服务端发送的帧不掩码。
+// 协商了 permessage-deflate(RFC 7692) 时，消息用 deflate 压缩并设置 RSV1，这里总是使用 no_context_takeover，每条消息独立压缩。
+// WebSocket 路由通过 RouterGroup 注册，分组的中间件(鉴权、日志等)在握手之前执行，e.g.
+//
+//	admin.WebSocket("/ws", nil, func(c *hint.Context, ws *hint.WebSocketConn) {
+//		for {
+//			typ, msg, err := ws.ReadMessage()
+//			if err != nil {
+//				return
+//			}
+//			_ = ws.WriteMessage(typ, msg)
+//		}
+//	})
+
+// The message types, see RFC 6455, section 11.8
+const (
+	continuationFrame = 0
+	TextMessage       = 1
+	BinaryMessage     = 2
+	CloseMessage      = 8
+	PingMessage       = 9
+	PongMessage       = 10
+)
+
+// Close codes, see RFC 6455, section 7.4.1 and the IANA WebSocket Close Code Number Registry
+const (
+	CloseNormalClosure           = 1000
+	CloseGoingAway               = 1001
+	CloseProtocolError           = 1002
+	CloseUnsupportedData         = 1003
+	CloseNoStatusReceived        = 1005
+	CloseAbnormalClosure         = 1006
+	CloseInvalidFramePayloadData = 1007
+	ClosePolicyViolation         = 1008
+	CloseMessageTooBig           = 1009
+	CloseMandatoryExtension      = 1010
+	CloseInternalServerErr       = 1011
+	CloseServiceRestart          = 1012
+	CloseTryAgainLater           = 1013
+	CloseBadGateway              = 1014
+)
+
+const (
+	websocketGUID          = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	maxControlPayload      = 125
+	defaultWebSocketLimit  = 1 << 20
+	defaultWebSocketBuffer = 4096
+)
+
+var (
+	ErrWebSocketCloseSent = errors.New("websocket: close sent")
+	ErrWebSocketHandshake = errors.New("websocket: bad handshake")
+	ErrWebSocketOrigin    = errors.New("websocket: origin is not allowed")
+)
+
+// CloseError is returned by ReadMessage when a close frame is received or the connection is failed
+type CloseError struct {
+	Code int
+	Text string
+}
+
+func (e *CloseError) Error() string {
+	return "websocket: close " + strconv.Itoa(e.Code) + " " + e.Text
+}
+
+// IsCloseError reports whether err is a *CloseError with one of the codes
+func IsCloseError(err error, codes ...int) bool {
+	var closeErr *CloseError
+	if !errors.As(err, &closeErr) {
+		return false
+	}
+	for _, code := range codes {
+		if closeErr.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// WebSocketUpgrader specifies the parameters for upgrading an HTTP connection to a WebSocket connection
+type WebSocketUpgrader struct {
+	// ReadLimit is the max size of a message in bytes (after decompression), 1MB by default
+	// the connection is closed with CloseMessageTooBig when it's exceeded
+	ReadLimit int64
+	// ReadBufferSize and WriteBufferSize are the sizes of I/O buffers, 4096 by default
+	ReadBufferSize  int
+	WriteBufferSize int
+	// Subprotocols are the supported protocols in the order of preference
+	Subprotocols []string
+	// CheckOrigin returns true when the Origin header is acceptable
+	// the origin must be the host of the request by default, requests without Origin are accepted
+	CheckOrigin func(r *http.Request) bool
+	// EnableCompression negotiates permessage-deflate with the client
+	EnableCompression bool
+}
+
+// WebSocket registers a GET route which upgrades the connection and calls handler
+// the connection is closed after handler returns, the Context must not be used after that
+func (group *RouterGroup) WebSocket(pattern string, upgrader *WebSocketUpgrader, handler func(c *Context, ws *WebSocketConn)) {
+	group.GET(pattern, func(c *Context) {
+		ws, err := c.UpgradeWebSocket(upgrader)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		handler(c, ws)
+	})
+}
+
+// UpgradeWebSocket upgrades the connection to the WebSocket protocol, nil upgrader means the default options
+// the error response is written and the error is attached to Context when the handshake fails
+func (c *Context) UpgradeWebSocket(upgrader *WebSocketUpgrader) (*WebSocketConn, error) {
+	if upgrader == nil {
+		upgrader = &WebSocketUpgrader{}
+	}
+	return upgrader.Upgrade(c)
+}
+
+// Upgrade upgrades the connection of c to the WebSocket protocol, see Context.UpgradeWebSocket
+func (u *WebSocketUpgrader) Upgrade(c *Context) (*WebSocketConn, error) {
+	fail := func(code int, err error) (*WebSocketConn, error) {
+		c.Error(err)
+		c.Fail(code, err.Error())
+		return nil, err
+	}
+	req := c.Req
+	if req.Method != http.MethodGet {
+		return fail(http.StatusMethodNotAllowed, fmt.Errorf("%w: method is not GET", ErrWebSocketHandshake))
+	}
+	if !headerContainsToken(req.Header, "Connection", "upgrade") || !headerContainsToken(req.Header, "Upgrade", "websocket") {
+		return fail(http.StatusBadRequest, fmt.Errorf("%w: not a websocket upgrade request", ErrWebSocketHandshake))
+	}
+	if req.Header.Get("Sec-WebSocket-Version") != "13" {
+		c.Writer.Header().Set("Sec-WebSocket-Version", "13")
+		return fail(http.StatusUpgradeRequired, fmt.Errorf("%w: unsupported version", ErrWebSocketHandshake))
+	}
+	key := req.Header.Get("Sec-WebSocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		return fail(http.StatusBadRequest, fmt.Errorf("%w: invalid Sec-WebSocket-Key", ErrWebSocketHandshake))
+	}
+	checkOrigin := u.CheckOrigin
+	if checkOrigin == nil {
+		checkOrigin = checkSameOrigin
+	}
+	if !checkOrigin(req) {
+		return fail(http.StatusForbidden, ErrWebSocketOrigin)
+	}
+	if c.Writer.Written() {
+		return nil, fmt.Errorf("%w: the response has been written", ErrWebSocketHandshake)
+	}
+
+	subprotocol := u.selectSubprotocol(req)
+	compress := u.EnableCompression && acceptPerMessageDeflate(req.Header)
+
+	// the status is recorded for the access log, the response is written after hijacking
+	c.Status(http.StatusSwitchingProtocols)
+	netConn, brw, err := c.Writer.Hijack()
+	if err != nil {
+		return fail(http.StatusInternalServerError, err)
+	}
+	// the deadlines set by http.Server (ReadTimeout/WriteTimeout) are cleared
+	_ = netConn.SetDeadline(time.Time{})
+
+	var resp bytes.Buffer
+	resp.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+	resp.WriteString("Sec-WebSocket-Accept: " + websocketAccept(key) + "\r\n")
+	if subprotocol != "" {
+		resp.WriteString("Sec-WebSocket-Protocol: " + subprotocol + "\r\n")
+	}
+	if compress {
+		resp.WriteString("Sec-WebSocket-Extensions: permessage-deflate; server_no_context_takeover; client_no_context_takeover\r\n")
+	}
+	// headers set by middlewares, e.g. Set-Cookie, X-Request-ID
+	for k, values := range c.Writer.Header() {
+		switch k {
+		case "Upgrade", "Connection", "Sec-Websocket-Accept", "Sec-Websocket-Protocol", "Sec-Websocket-Extensions", "Content-Type":
+			continue
+		}
+		for _, v := range values {
+			resp.WriteString(k + ": " + strings.NewReplacer("\r", "", "\n", "").Replace(v) + "\r\n")
+		}
+	}
+	resp.WriteString("\r\n")
+	if _, err = netConn.Write(resp.Bytes()); err != nil {
+		_ = netConn.Close()
+		return nil, err
+	}
+
+	readLimit := u.ReadLimit
+	if readLimit <= 0 {
+		readLimit = defaultWebSocketLimit
+	}
+	readBufferSize, writeBufferSize := u.ReadBufferSize, u.WriteBufferSize
+	if readBufferSize <= 0 {
+		readBufferSize = defaultWebSocketBuffer
+	}
+	if writeBufferSize <= 0 {
+		writeBufferSize = defaultWebSocketBuffer
+	}
+	br := brw.Reader
+	if br.Buffered() == 0 {
+		// nothing is read ahead, so that the reader can be replaced with the configured size
+		br = bufio.NewReaderSize(netConn, readBufferSize)
+	}
+	return &WebSocketConn{
+		conn:        netConn,
+		br:          br,
+		bw:          bufio.NewWriterSize(netConn, writeBufferSize),
+		subprotocol: subprotocol,
+		compress:    compress,
+		readLimit:   readLimit,
+	}, nil
+}
+
+func (u *WebSocketUpgrader) selectSubprotocol(req *http.Request) string {
+	requested := headerTokens(req.Header, "Sec-WebSocket-Protocol")
+	for _, supported := range u.Subprotocols {
+		for _, p := range requested {
+			if p == supported {
+				return p
+			}
+		}
+	}
+	return ""
+}
+
+// checkSameOrigin accepts the requests without Origin or from the same host
+func checkSameOrigin(req *http.Request) bool {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, req.Host)
+}
+
+// acceptPerMessageDeflate reports whether the client offers permessage-deflate which can be accepted
+// offers limiting the window of the server are declined, as compress/flate always uses a 32KB window
+func acceptPerMessageDeflate(header http.Header) bool {
+	for _, offer := range headerTokens(header, "Sec-WebSocket-Extensions") {
+		params := strings.Split(offer, ";")
+		if strings.TrimSpace(params[0]) != "permessage-deflate" {
+			continue
+		}
+		ok := true
+		for _, param := range params[1:] {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			switch name {
+			case "server_no_context_takeover", "client_no_context_takeover", "client_max_window_bits":
+			case "server_max_window_bits":
+				ok = strings.Trim(value, `"`) == "15"
+			default:
+				ok = false
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// headerTokens splits the comma-separated values of header
+func headerTokens(header http.Header, name string) []string {
+	var tokens []string
+	for _, value := range header.Values(name) {
+		for _, token := range strings.Split(value, ",") {
+			if token = strings.TrimSpace(token); token != "" {
+				tokens = append(tokens, token)
+			}
+		}
+	}
+	return tokens
+}
+
+func headerContainsToken(header http.Header, name, token string) bool {
+	for _, t := range headerTokens(header, name) {
+		if strings.EqualFold(t, token) {
+			return true
+		}
+	}
+	return false
+}
+
+// websocketAccept returns base64(sha1(key + GUID))
+func websocketAccept(key string) string {
+	h := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// WebSocketConn is a WebSocket connection
+// one goroutine may read and others may write concurrently, as writes are serialized
+type WebSocketConn struct {
+	conn        net.Conn
+	br          *bufio.Reader
+	subprotocol string
+	compress    bool
+	readLimit   int64
+	// readErr is the permanent error of reading, the connection is unusable after that
+	readErr     error
+	pongHandler func(appData []byte)
+
+	writeMu   sync.Mutex
+	bw        *bufio.Writer
+	closeSent bool
+}
+
+// Subprotocol returns the negotiated protocol
+func (ws *WebSocketConn) Subprotocol() string {
+	return ws.subprotocol
+}
+
+// RemoteAddr returns the remote network address
+func (ws *WebSocketConn) RemoteAddr() net.Addr {
+	return ws.conn.RemoteAddr()
+}
+
+// SetReadLimit sets the max size of a message in bytes
+func (ws *WebSocketConn) SetReadLimit(limit int64) {
+	ws.readLimit = limit
+}
+
+// SetReadDeadline sets the deadline of reading, e.g. to detect a dead peer together with ping/pong
+func (ws *WebSocketConn) SetReadDeadline(t time.Time) error {
+	return ws.conn.SetReadDeadline(t)
+}
+
+// SetWriteDeadline sets the deadline of writing
+func (ws *WebSocketConn) SetWriteDeadline(t time.Time) error {
+	return ws.conn.SetWriteDeadline(t)
+}
+
+// SetPongHandler sets the handler called by ReadMessage when a pong is received
+// pings are answered by ReadMessage automatically
+func (ws *WebSocketConn) SetPongHandler(h func(appData []byte)) {
+	ws.pongHandler = h
+}
+
+type wsFrame struct {
+	fin     bool
+	rsv1    bool
+	opcode  int
+	payload []byte
+}
+
+// ReadMessage reads the next text or binary message, control frames are handled meanwhile
+// a *CloseError is returned when the peer closes the connection or the connection is failed
+func (ws *WebSocketConn) ReadMessage() (messageType int, data []byte, err error) {
+	if ws.readErr != nil {
+		return 0, nil, ws.readErr
+	}
+	started, compressed := false, false
+	for {
+		f, err := ws.readFrame()
+		if err != nil {
+			return 0, nil, ws.failRead(err)
+		}
+		switch f.opcode {
+		case PingMessage:
+			if err = ws.WriteControl(PongMessage, f.payload); err != nil && !errors.Is(err, ErrWebSocketCloseSent) {
+				return 0, nil, ws.failRead(err)
+			}
+			continue
+		case PongMessage:
+			if ws.pongHandler != nil {
+				ws.pongHandler(f.payload)
+			}
+			continue
+		case CloseMessage:
+			return 0, nil, ws.handleClose(f.payload)
+		case TextMessage, BinaryMessage:
+			if started {
+				return 0, nil, ws.failRead(&CloseError{Code: CloseProtocolError, Text: "new message before the fragmented message finished"})
+			}
+			started, compressed, messageType = true, f.rsv1, f.opcode
+		case continuationFrame:
+			if !started {
+				return 0, nil, ws.failRead(&CloseError{Code: CloseProtocolError, Text: "unexpected continuation frame"})
+			}
+		}
+		if int64(len(data)+len(f.payload)) > ws.readLimit {
+			return 0, nil, ws.failRead(&CloseError{Code: CloseMessageTooBig, Text: "message too big"})
+		}
+		data = append(data, f.payload...)
+		if f.fin {
+			break
+		}
+	}
+	if compressed {
+		if data, err = ws.decompress(data); err != nil {
+			return 0, nil, ws.failRead(err)
+		}
+	}
+	if messageType == TextMessage && !utf8.Valid(data) {
+		return 0, nil, ws.failRead(&CloseError{Code: CloseInvalidFramePayloadData, Text: "invalid UTF-8 in text message"})
+	}
+	return messageType, data, nil
+}
+
+// ReadJSON reads the next message and decodes it as JSON
+func (ws *WebSocketConn) ReadJSON(v interface{}) error {
+	_, data, err := ws.ReadMessage()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// readFrame reads and validates a frame
+func (ws *WebSocketConn) readFrame() (wsFrame, error) {
+	var f wsFrame
+	var head [2]byte
+	if _, err := io.ReadFull(ws.br, head[:]); err != nil {
+		return f, err
+	}
+	f.fin = head[0]&0x80 != 0
+	f.rsv1 = head[0]&0x40 != 0
+	f.opcode = int(head[0] & 0x0f)
+	masked := head[1]&0x80 != 0
+	length := int64(head[1] & 0x7f)
+
+	protocolError := func(text string) (wsFrame, error) {
+		return f, &CloseError{Code: CloseProtocolError, Text: text}
+	}
+	if head[0]&0x30 != 0 {
+		return protocolError("unexpected reserved bits")
+	}
+	switch f.opcode {
+	case continuationFrame:
+		if f.rsv1 {
+			return protocolError("RSV1 set on continuation frame")
+		}
+	case TextMessage, BinaryMessage:
+		if f.rsv1 && !ws.compress {
+			return protocolError("RSV1 set without compression")
+		}
+	case CloseMessage, PingMessage, PongMessage:
+		if !f.fin || f.rsv1 || length > maxControlPayload {
+			return protocolError("invalid control frame")
+		}
+	default:
+		return protocolError("unknown opcode " + strconv.Itoa(f.opcode))
+	}
+	if !masked {
+		return protocolError("frame from client is not masked")
+	}
+
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(ws.br, ext[:]); err != nil {
+			return f, err
+		}
+		length = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(ws.br, ext[:]); err != nil {
+			return f, err
+		}
+		if ext[0]&0x80 != 0 {
+			return protocolError("invalid payload length")
+		}
+		length = int64(binary.BigEndian.Uint64(ext[:]))
+	}
+	if length > ws.readLimit {
+		return f, &CloseError{Code: CloseMessageTooBig, Text: "message too big"}
+	}
+	var mask [4]byte
+	if _, err := io.ReadFull(ws.br, mask[:]); err != nil {
+		return f, err
+	}
+	f.payload = make([]byte, length)
+	if _, err := io.ReadFull(ws.br, f.payload); err != nil {
+		return f, err
+	}
+	for i := range f.payload {
+		f.payload[i] ^= mask[i%4]
+	}
+	return f, nil
+}
+
+// handleClose replies the close frame and closes the connection
+func (ws *WebSocketConn) handleClose(payload []byte) error {
+	closeErr := &CloseError{Code: CloseNoStatusReceived}
+	switch {
+	case len(payload) == 1:
+		return ws.failRead(&CloseError{Code: CloseProtocolError, Text: "invalid close payload"})
+	case len(payload) >= 2:
+		closeErr.Code = int(binary.BigEndian.Uint16(payload))
+		closeErr.Text = string(payload[2:])
+		if !validCloseCode(closeErr.Code) {
+			return ws.failRead(&CloseError{Code: CloseProtocolError, Text: "invalid close code"})
+		}
+		if !utf8.ValidString(closeErr.Text) {
+			return ws.failRead(&CloseError{Code: CloseInvalidFramePayloadData, Text: "invalid UTF-8 in close reason"})
+		}
+	}
+	reply := closeErr.Code
+	if reply == CloseNoStatusReceived {
+		reply = CloseNormalClosure
+	}
+	_ = ws.WriteControl(CloseMessage, FormatCloseMessage(reply, ""))
+	_ = ws.conn.Close()
+	ws.readErr = closeErr
+	return closeErr
+}
+
+// failRead fails the connection with the close code of err, CloseAbnormalClosure for I/O errors
+func (ws *WebSocketConn) failRead(err error) error {
+	var closeErr *CloseError
+	if errors.As(err, &closeErr) {
+		_ = ws.WriteControl(CloseMessage, FormatCloseMessage(closeErr.Code, closeErr.Text))
+	} else {
+		err = &CloseError{Code: CloseAbnormalClosure, Text: err.Error()}
+	}
+	_ = ws.conn.Close()
+	ws.readErr = err
+	return err
+}
+
+// validCloseCode reports whether the code can be sent in a close frame
+func validCloseCode(code int) bool {
+	switch {
+	case code >= 1000 && code <= 1003, code >= 1007 && code <= 1014:
+		return true
+	case code >= 3000 && code <= 4999:
+		return true
+	}
+	return false
+}
+
+// FormatCloseMessage formats code and text as the payload of a close frame
+// the text is truncated to fit the control frame
+func FormatCloseMessage(code int, text string) []byte {
+	if code == CloseNoStatusReceived {
+		return nil
+	}
+	if len(text) > maxControlPayload-2 {
+		text = text[:maxControlPayload-2]
+	}
+	payload := make([]byte, 2+len(text))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	copy(payload[2:], text)
+	return payload
+}
+
+var (
+	flateWriterPool = sync.Pool{New: func() interface{} {
+		w, _ := flate.NewWriter(nil, flate.DefaultCompression)
+		return w
+	}}
+	flateReaderPool = sync.Pool{New: func() interface{} {
+		return flate.NewReader(nil)
+	}}
+)
+
+// deflateTail is removed from the compressed message by the sender, see RFC 7692, section 7.2.1
+var deflateTail = []byte{0x00, 0x00, 0xff, 0xff}
+
+func (ws *WebSocketConn) decompress(data []byte) ([]byte, error) {
+	// the final empty stored block makes the reader return io.EOF
+	src := io.MultiReader(bytes.NewReader(data), bytes.NewReader(deflateTail), bytes.NewReader([]byte{0x01, 0x00, 0x00, 0xff, 0xff}))
+	fr := flateReaderPool.Get().(io.ReadCloser)
+	defer flateReaderPool.Put(fr)
+	if err := fr.(flate.Resetter).Reset(src, nil); err != nil {
+		return nil, err
+	}
+	out, err := io.ReadAll(io.LimitReader(fr, ws.readLimit+1))
+	if err != nil {
+		return nil, &CloseError{Code: CloseInvalidFramePayloadData, Text: "invalid compressed data"}
+	}
+	if int64(len(out)) > ws.readLimit {
+		return nil, &CloseError{Code: CloseMessageTooBig, Text: "message too big"}
+	}
+	return out, nil
+}
+
+func compressMessage(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	fw := flateWriterPool.Get().(*flate.Writer)
+	defer flateWriterPool.Put(fw)
+	fw.Reset(&buf)
+	if _, err := fw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := fw.Flush(); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), deflateTail), nil
+}
+
+// WriteMessage writes a message, text and binary messages are compressed when permessage-deflate is negotiated
+func (ws *WebSocketConn) WriteMessage(messageType int, data []byte) error {
+	switch messageType {
+	case TextMessage, BinaryMessage:
+	case CloseMessage, PingMessage, PongMessage:
+		return ws.WriteControl(messageType, data)
+	default:
+		return fmt.Errorf("websocket: unknown message type %d", messageType)
+	}
+	if !ws.compress {
+		return ws.writeFrame(messageType, false, data)
+	}
+	compressed, err := compressMessage(data)
+	if err != nil {
+		return err
+	}
+	return ws.writeFrame(messageType, true, compressed)
+}
+
+// WriteJSON writes v as a JSON text message
+func (ws *WebSocketConn) WriteJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return ws.WriteMessage(TextMessage, data)
+}
+
+// WriteControl writes a close, ping or pong frame, the payload must not be longer than 125 bytes
+func (ws *WebSocketConn) WriteControl(messageType int, data []byte) error {
+	if messageType != CloseMessage && messageType != PingMessage && messageType != PongMessage {
+		return fmt.Errorf("websocket: %d is not a control message", messageType)
+	}
+	if len(data) > maxControlPayload {
+		return errors.New("websocket: control frame payload is too long")
+	}
+	return ws.writeFrame(messageType, false, data)
+}
+
+// Ping sends a ping, the peer replies a pong which is handled by the pong handler
+func (ws *WebSocketConn) Ping(data []byte) error {
+	return ws.WriteControl(PingMessage, data)
+}
+
+func (ws *WebSocketConn) writeFrame(opcode int, rsv1 bool, payload []byte) error {
+	ws.writeMu.Lock()
+	defer ws.writeMu.Unlock()
+	if ws.closeSent {
+		return ErrWebSocketCloseSent
+	}
+	var head [10]byte
+	head[0] = 0x80 | byte(opcode)
+	if rsv1 {
+		head[0] |= 0x40
+	}
+	n := 2
+	switch length := len(payload); {
+	case length <= 125:
+		head[1] = byte(length)
+	case length <= 0xffff:
+		head[1] = 126
+		binary.BigEndian.PutUint16(head[2:], uint16(length))
+		n += 2
+	default:
+		head[1] = 127
+		binary.BigEndian.PutUint64(head[2:], uint64(length))
+		n += 8
+	}
+	if opcode == CloseMessage {
+		ws.closeSent = true
+	}
+	if _, err := ws.bw.Write(head[:n]); err != nil {
+		return err
+	}
+	if _, err := ws.bw.Write(payload); err != nil {
+		return err
+	}
+	return ws.bw.Flush()
+}
+
+// CloseWithCode sends a close frame with code and text, and closes the connection
+func (ws *WebSocketConn) CloseWithCode(code int, text string) error {
+	err := ws.WriteControl(CloseMessage, FormatCloseMessage(code, text))
+	if closeErr := ws.conn.Close(); err == nil || errors.Is(err, ErrWebSocketCloseSent) {
+		err = closeErr
+	}
+	return err
+}
+
+// Close sends a normal close frame if it's not sent yet, and closes the connection
+func (ws *WebSocketConn) Close() error {
+	_ = ws.WriteControl(CloseMessage, FormatCloseMessage(CloseNormalClosure, ""))
+	return ws.conn.Close()
+}
@@ -0,0 +1,305 @@
+package hint
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// wsClient is a minimal WebSocket client for tests
+type wsClient struct {
+	t    *testing.T
+	conn net.Conn
+	br   *bufio.Reader
+	resp *http.Response
+}
+
+func dialWebSocket(t *testing.T, srv *httptest.Server, path string, header http.Header) *wsClient {
+	t.Helper()
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if err = req.Write(conn); err != nil {
+		t.Fatal(err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &wsClient{t: t, conn: conn, br: br, resp: resp}
+}
+
+func (ws *wsClient) writeFrame(fin bool, rsv1 bool, opcode int, payload []byte) {
+	ws.t.Helper()
+	var buf bytes.Buffer
+	b0 := byte(opcode)
+	if fin {
+		b0 |= 0x80
+	}
+	if rsv1 {
+		b0 |= 0x40
+	}
+	buf.WriteByte(b0)
+	switch {
+	case len(payload) <= 125:
+		buf.WriteByte(0x80 | byte(len(payload)))
+	case len(payload) <= 0xffff:
+		buf.WriteByte(0x80 | 126)
+		_ = binary.Write(&buf, binary.BigEndian, uint16(len(payload)))
+	default:
+		buf.WriteByte(0x80 | 127)
+		_ = binary.Write(&buf, binary.BigEndian, uint64(len(payload)))
+	}
+	mask := []byte{1, 2, 3, 4}
+	buf.Write(mask)
+	for i, b := range payload {
+		buf.WriteByte(b ^ mask[i%4])
+	}
+	if _, err := ws.conn.Write(buf.Bytes()); err != nil {
+		ws.t.Fatal(err)
+	}
+}
+
+func (ws *wsClient) readFrame() (opcode int, rsv1 bool, payload []byte) {
+	ws.t.Helper()
+	var head [2]byte
+	if _, err := io.ReadFull(ws.br, head[:]); err != nil {
+		ws.t.Fatal(err)
+	}
+	if head[1]&0x80 != 0 {
+		ws.t.Fatal("frame from server is masked")
+	}
+	length := uint64(head[1] & 0x7f)
+	switch length {
+	case 126:
+		var n uint16
+		_ = binary.Read(ws.br, binary.BigEndian, &n)
+		length = uint64(n)
+	case 127:
+		_ = binary.Read(ws.br, binary.BigEndian, &length)
+	}
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(ws.br, payload); err != nil {
+		ws.t.Fatal(err)
+	}
+	return int(head[0] & 0x0f), head[0]&0x40 != 0, payload
+}
+
+func (ws *wsClient) expectClose(code int) {
+	ws.t.Helper()
+	opcode, _, payload := ws.readFrame()
+	if opcode != CloseMessage || len(payload) < 2 || int(binary.BigEndian.Uint16(payload)) != code {
+		ws.t.Fatalf("expect close %d, got opcode %d payload %q", code, opcode, payload)
+	}
+}
+
+func newWebSocketServer(t *testing.T, upgrader *WebSocketUpgrader, handler func(c *Context, ws *WebSocketConn)) *httptest.Server {
+	e := New()
+	group := e.Group("/chat")
+	group.Use(func(c *Context) {
+		if c.Query("token") != "secret" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.SetHeader("X-Group", "chat")
+		c.Next()
+	})
+	group.WebSocket("/ws", upgrader, handler)
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func echo(c *Context, ws *WebSocketConn) {
+	for {
+		typ, msg, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		if err = ws.WriteMessage(typ, msg); err != nil {
+			return
+		}
+	}
+}
+
+func TestWebSocketEcho(t *testing.T) {
+	srv := newWebSocketServer(t, &WebSocketUpgrader{Subprotocols: []string{"v2", "v1"}}, echo)
+
+	if ws := dialWebSocket(t, srv, "/chat/ws", nil); ws.resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("group middleware is not applied, status %d", ws.resp.StatusCode)
+	}
+
+	ws := dialWebSocket(t, srv, "/chat/ws?token=secret", http.Header{"Sec-Websocket-Protocol": {"v1, v2"}})
+	if ws.resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("unexpected status %d", ws.resp.StatusCode)
+	}
+	h := ws.resp.Header
+	if h.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" || h.Get("Sec-WebSocket-Protocol") != "v2" || h.Get("X-Group") != "chat" {
+		t.Fatalf("unexpected handshake %v", h)
+	}
+
+	ws.writeFrame(true, false, TextMessage, []byte("hello"))
+	if opcode, _, payload := ws.readFrame(); opcode != TextMessage || string(payload) != "hello" {
+		t.Fatalf("unexpected echo %d %q", opcode, payload)
+	}
+	large := bytes.Repeat([]byte{0xff}, 70000)
+	ws.writeFrame(true, false, BinaryMessage, large)
+	if opcode, _, payload := ws.readFrame(); opcode != BinaryMessage || !bytes.Equal(payload, large) {
+		t.Fatalf("unexpected echo %d of %d bytes", opcode, len(payload))
+	}
+
+	// fragmented message with a ping in between
+	ws.writeFrame(false, false, TextMessage, []byte("hel"))
+	ws.writeFrame(true, false, PingMessage, []byte("p"))
+	ws.writeFrame(false, false, continuationFrame, []byte("lo "))
+	ws.writeFrame(true, false, continuationFrame, []byte("world"))
+	if opcode, _, payload := ws.readFrame(); opcode != PongMessage || string(payload) != "p" {
+		t.Fatalf("unexpected pong %d %q", opcode, payload)
+	}
+	if opcode, _, payload := ws.readFrame(); opcode != TextMessage || string(payload) != "hello world" {
+		t.Fatalf("unexpected echo %d %q", opcode, payload)
+	}
+
+	ws.writeFrame(true, false, CloseMessage, FormatCloseMessage(CloseGoingAway, "bye"))
+	ws.expectClose(CloseGoingAway)
+}
+
+func TestWebSocketClose(t *testing.T) {
+	closed := make(chan error, 1)
+	srv := newWebSocketServer(t, nil, func(c *Context, ws *WebSocketConn) {
+		_, _, err := ws.ReadMessage()
+		closed <- err
+	})
+	ws := dialWebSocket(t, srv, "/chat/ws?token=secret", nil)
+	ws.writeFrame(true, false, CloseMessage, nil)
+	ws.expectClose(CloseNormalClosure)
+	if err := <-closed; !IsCloseError(err, CloseNoStatusReceived) {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestWebSocketProtocolErrors(t *testing.T) {
+	sendClose := func(code int) func(ws *wsClient) {
+		return func(ws *wsClient) { ws.writeFrame(true, false, CloseMessage, FormatCloseMessage(code, "")) }
+	}
+	srv := newWebSocketServer(t, &WebSocketUpgrader{ReadLimit: 10}, echo)
+	tests := []struct {
+		name string
+		send func(ws *wsClient)
+		code int
+	}{
+		{"too big", func(ws *wsClient) { ws.writeFrame(true, false, TextMessage, make([]byte, 11)) }, CloseMessageTooBig},
+		{"too big fragments", func(ws *wsClient) {
+			ws.writeFrame(false, false, BinaryMessage, make([]byte, 6))
+			ws.writeFrame(true, false, continuationFrame, make([]byte, 6))
+		}, CloseMessageTooBig},
+		{"invalid utf8", func(ws *wsClient) { ws.writeFrame(true, false, TextMessage, []byte{0xff}) }, CloseInvalidFramePayloadData},
+		{"unexpected continuation", func(ws *wsClient) { ws.writeFrame(true, false, continuationFrame, []byte("x")) }, CloseProtocolError},
+		{"fragmented control", func(ws *wsClient) { ws.writeFrame(false, false, PingMessage, nil) }, CloseProtocolError},
+		{"rsv1 without compression", func(ws *wsClient) { ws.writeFrame(true, true, TextMessage, []byte("x")) }, CloseProtocolError},
+		{"reserved close code", func(ws *wsClient) { ws.writeFrame(true, false, CloseMessage, []byte{0x03, 0xed}) }, CloseProtocolError},
+		// registered codes are echoed to complete the close handshake
+		{"service restart", sendClose(CloseServiceRestart), CloseServiceRestart},
+		{"try again later", sendClose(CloseTryAgainLater), CloseTryAgainLater},
+		{"bad gateway", sendClose(CloseBadGateway), CloseBadGateway},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := dialWebSocket(t, srv, "/chat/ws?token=secret", nil)
+			tt.send(ws)
+			ws.expectClose(tt.code)
+		})
+	}
+
+	t.Run("unmasked", func(t *testing.T) {
+		ws := dialWebSocket(t, srv, "/chat/ws?token=secret", nil)
+		_, _ = ws.conn.Write([]byte{0x81, 0x01, 'x'})
+		ws.expectClose(CloseProtocolError)
+	})
+}
+
+func TestWebSocketCompression(t *testing.T) {
+	srv := newWebSocketServer(t, &WebSocketUpgrader{EnableCompression: true}, echo)
+	ws := dialWebSocket(t, srv, "/chat/ws?token=secret", http.Header{
+		"Sec-Websocket-Extensions": {"permessage-deflate; server_max_window_bits=10, permessage-deflate; client_max_window_bits"},
+	})
+	if ext := ws.resp.Header.Get("Sec-WebSocket-Extensions"); !strings.HasPrefix(ext, "permessage-deflate") {
+		t.Fatalf("compression is not negotiated: %q", ext)
+	}
+
+	msg := strings.Repeat("compress me ", 100)
+	var buf bytes.Buffer
+	fw, _ := flate.NewWriter(&buf, flate.BestCompression)
+	_, _ = fw.Write([]byte(msg))
+	_ = fw.Flush()
+	ws.writeFrame(true, true, TextMessage, bytes.TrimSuffix(buf.Bytes(), []byte{0, 0, 0xff, 0xff}))
+
+	opcode, rsv1, payload := ws.readFrame()
+	if opcode != TextMessage || !rsv1 || len(payload) >= len(msg) {
+		t.Fatalf("unexpected frame %d rsv1=%v of %d bytes", opcode, rsv1, len(payload))
+	}
+	fr := flate.NewReader(io.MultiReader(bytes.NewReader(payload), bytes.NewReader([]byte{0, 0, 0xff, 0xff, 1, 0, 0, 0xff, 0xff})))
+	if got, err := io.ReadAll(fr); err != nil || string(got) != msg {
+		t.Fatalf("unexpected message %q, %v", got, err)
+	}
+
+	// offers limiting the window of the server are declined
+	ws = dialWebSocket(t, srv, "/chat/ws?token=secret", http.Header{
+		"Sec-Websocket-Extensions": {"permessage-deflate; server_max_window_bits=10"},
+	})
+	if ext := ws.resp.Header.Get("Sec-WebSocket-Extensions"); ext != "" {
+		t.Fatalf("unexpected extension %q", ext)
+	}
+}
+
+func TestWebSocketHandshakeErrors(t *testing.T) {
+	e := New()
+	e.WebSocket("/ws", nil, echo)
+	upgrade := func(mod func(req *http.Request)) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+		req.Header.Set("Connection", "keep-alive, Upgrade")
+		req.Header.Set("Upgrade", "websocket")
+		req.Header.Set("Sec-WebSocket-Version", "13")
+		req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+		mod(req)
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		return w
+	}
+	if w := upgrade(func(req *http.Request) { req.Header.Del("Upgrade") }); w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status %d", w.Code)
+	}
+	if w := upgrade(func(req *http.Request) { req.Header.Set("Sec-WebSocket-Version", "8") }); w.Code != http.StatusUpgradeRequired || w.Header().Get("Sec-WebSocket-Version") != "13" {
+		t.Fatalf("unexpected response %d %v", w.Code, w.Header())
+	}
+	if w := upgrade(func(req *http.Request) { req.Header.Set("Sec-WebSocket-Key", "short") }); w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status %d", w.Code)
+	}
+	if w := upgrade(func(req *http.Request) { req.Header.Set("Origin", "http://evil.com") }); w.Code != http.StatusForbidden {
+		t.Fatalf("unexpected status %d", w.Code)
+	}
+	// httptest.ResponseRecorder can't be hijacked
+	if w := upgrade(func(req *http.Request) { req.Header.Set("Origin", "http://example.com") }); w.Code != http.StatusInternalServerError {
+		t.Fatalf("unexpected status %d", w.Code)
+	}
+}
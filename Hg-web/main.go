@@ -16,7 +16,7 @@ func onlyForV2() hint.HandlerFunc {
 		// if a server error occurred
 		c.Fail(500, "Internal Server Error")
 		// Calculate resolution time
-		log.Printf("[%d] %s in %v for group v2", c.StatusCode, c.Req.RequestURI, time.Since(t))
+		log.Printf("[%d] %s in %v for group v2", c.Writer.Status(), c.Req.RequestURI, time.Since(t))
 	}
 }
 